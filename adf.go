@@ -0,0 +1,299 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// adfNode represents a node of a document in the Atlassian Document Format (ADF) used by Jira Cloud.
+type adfNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []adfNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []adfMark      `json:"marks,omitempty"`
+}
+
+type adfMark struct {
+	Type string `json:"type"`
+}
+
+var (
+	wikiHeadingPattern  = regexp.MustCompile(`^h([1-6])\.\s+(.*)$`)
+	wikiListItemPattern = regexp.MustCompile(`^[*-]\s+(.*)$`)
+	wikiPanelPattern    = regexp.MustCompile(`^\{panel(?::([^}]*))?\}(.*)$`)
+	wikiCodePattern     = regexp.MustCompile(`^\{(noformat|code)(?::[^}]*)?\}$`)
+)
+
+// isADFDocument checks whether a given string already is a JSON-encoded ADF document.
+func isADFDocument(s string) bool {
+	var doc struct {
+		Type string `json:"type"`
+	}
+
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "{") && json.Unmarshal([]byte(s), &doc) == nil && doc.Type == "doc"
+}
+
+// renderADF converts text formatted with the Jira wiki markup to a JSON-encoded ADF document.
+// Only the subset of the markup used by the built-in templates is supported: headings, tables, panels,
+// code blocks, bulleted lists, *strong* and _emphasized_ text, and escaped characters.
+// Everything else is preserved as plain text.
+func renderADF(markup string) ([]byte, error) {
+	doc := adfNode{
+		Type:    "doc",
+		Version: 1,
+		Content: parseWikiBlocks(strings.Split(markup, "\n")),
+	}
+
+	if doc.Content == nil {
+		// An ADF document needs at least one block
+		doc.Content = []adfNode{{Type: "paragraph"}}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func parseWikiBlocks(lines []string) (blocks []adfNode) {
+	var paragraph []string
+	var table, list *adfNode
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			node := adfNode{Type: "paragraph"}
+			for i, line := range paragraph {
+				if i > 0 {
+					node.Content = append(node.Content, adfNode{Type: "hardBreak"})
+				}
+				node.Content = append(node.Content, parseWikiInline(line)...)
+			}
+			blocks = append(blocks, node)
+			paragraph = nil
+		}
+
+		for _, node := range []*adfNode{table, list} {
+			if node != nil {
+				blocks = append(blocks, *node)
+			}
+		}
+		table, list = nil, nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		if m := wikiPanelPattern.FindStringSubmatch(line); m != nil {
+			flush()
+
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "{panel}" {
+				end++
+			}
+
+			inner := lines[i+1 : min(end, len(lines))]
+			if strings.TrimSpace(m[2]) != "" {
+				inner = append([]string{m[2]}, inner...)
+			}
+			blocks = append(blocks, newADFPanel(m[1], parseWikiBlocks(inner)))
+			i = end
+			continue
+		}
+
+		if m := wikiCodePattern.FindStringSubmatch(line); m != nil {
+			flush()
+
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "{"+m[1]+"}" {
+				end++
+			}
+
+			// The contents of code blocks are kept as they are, without interpreting the markup
+			code := adfNode{Type: "codeBlock"}
+			if text := strings.Join(lines[i+1:min(end, len(lines))], "\n"); text != "" {
+				code.Content = []adfNode{{Type: "text", Text: text}}
+			}
+			blocks = append(blocks, code)
+			i = end
+			continue
+		}
+
+		if m := wikiHeadingPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, adfNode{
+				Type:    "heading",
+				Attrs:   map[string]any{"level": int(m[1][0] - '0')},
+				Content: parseWikiInline(m[2]),
+			})
+			continue
+		}
+
+		if strings.HasPrefix(line, "|") {
+			if table == nil {
+				flush()
+				table = &adfNode{Type: "table", Attrs: map[string]any{"layout": "default"}}
+			}
+			table.Content = append(table.Content, parseWikiTableRow(line))
+			continue
+		}
+
+		if m := wikiListItemPattern.FindStringSubmatch(line); m != nil {
+			if list == nil {
+				flush()
+				list = &adfNode{Type: "bulletList"}
+			}
+			list.Content = append(list.Content, adfNode{
+				Type:    "listItem",
+				Content: []adfNode{{Type: "paragraph", Content: parseWikiInline(m[1])}},
+			})
+			continue
+		}
+
+		if line == "" {
+			flush()
+			continue
+		}
+
+		if table != nil || list != nil {
+			flush()
+		}
+		paragraph = append(paragraph, line)
+	}
+
+	flush()
+
+	return blocks
+}
+
+// newADFPanel creates an information panel. ADF panels do not have titles, so the title
+// given in the panel parameters (e.g. "{panel:title=Note}") is added as the first paragraph.
+func newADFPanel(params string, content []adfNode) adfNode {
+	for _, param := range strings.Split(params, "|") {
+		if title, ok := strings.CutPrefix(param, "title="); ok && title != "" {
+			heading := adfNode{Type: "paragraph", Content: []adfNode{{Type: "text", Text: title, Marks: []adfMark{{Type: "strong"}}}}}
+			content = append([]adfNode{heading}, content...)
+		}
+	}
+
+	if content == nil {
+		content = []adfNode{{Type: "paragraph"}}
+	}
+
+	return adfNode{Type: "panel", Attrs: map[string]any{"panelType": "info"}, Content: content}
+}
+
+// parseWikiTableRow converts a table row to ADF. Cells starting with "||" are header cells,
+// and cells starting with "|" are regular cells. Escaped separators ("\|") are kept in the cell.
+func parseWikiTableRow(line string) adfNode {
+	row := adfNode{Type: "tableRow"}
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); {
+		cellType := "tableCell"
+		if i+1 < len(runes) && runes[i+1] == '|' {
+			cellType = "tableHeader"
+			i++
+		}
+		i++
+
+		start := i
+		for i < len(runes) && runes[i] != '|' {
+			if runes[i] == '\\' {
+				i++
+			}
+			i++
+		}
+
+		text := strings.TrimSpace(string(runes[start:min(i, len(runes))]))
+		if i >= len(runes) && text == "" {
+			// Trailing separator of the row
+			break
+		}
+
+		paragraph := adfNode{Type: "paragraph", Content: parseWikiInline(text)}
+		row.Content = append(row.Content, adfNode{Type: cellType, Content: []adfNode{paragraph}})
+	}
+
+	return row
+}
+
+// parseWikiInline converts a single line of text to ADF text nodes, applying the strong ("*text*")
+// and emphasis ("_text_") marks and removing the backslashes from escaped characters.
+func parseWikiInline(s string) (nodes []adfNode) {
+	runes := []rune(s)
+	markTypes := map[rune]string{'*': "strong", '_': "em"}
+	active := map[rune]bool{}
+
+	var sb strings.Builder
+	emit := func() {
+		if sb.Len() == 0 {
+			return
+		}
+
+		node := adfNode{Type: "text", Text: sb.String()}
+		for _, symbol := range []rune{'*', '_'} {
+			if active[symbol] {
+				node.Marks = append(node.Marks, adfMark{Type: markTypes[symbol]})
+			}
+		}
+		nodes = append(nodes, node)
+		sb.Reset()
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r == '\\' && i+1 < len(runes) {
+			sb.WriteRune(runes[i+1])
+			i++
+			continue
+		}
+
+		if _, ok := markTypes[r]; ok {
+			closes := active[r] && i > 0 && !unicode.IsSpace(runes[i-1])
+			opens := !active[r] && (i == 0 || !isWikiWordRune(runes[i-1])) &&
+				i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && hasWikiClosingMark(runes[i+1:], r)
+
+			if closes || opens {
+				emit()
+				active[r] = opens
+				continue
+			}
+		}
+
+		sb.WriteRune(r)
+	}
+
+	emit()
+
+	return nodes
+}
+
+func isWikiWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func hasWikiClosingMark(runes []rune, symbol rune) bool {
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+
+		if runes[i] == symbol && i > 0 && !unicode.IsSpace(runes[i-1]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseWikiInline(t *testing.T) {
+	testCases := map[string][]adfNode{
+		"plain text": {{Type: "text", Text: "plain text"}},
+		"*Total*":    {{Type: "text", Text: "Total", Marks: []adfMark{{Type: "strong"}}}},
+		"🧮 *Total* count": {
+			{Type: "text", Text: "🧮 "},
+			{Type: "text", Text: "Total", Marks: []adfMark{{Type: "strong"}}},
+			{Type: "text", Text: " count"},
+		},
+		"_Only the first 50_":                      {{Type: "text", Text: "Only the first 50", Marks: []adfMark{{Type: "em"}}}},
+		"test_name_with_underscores and 2 * 3 = 6": {{Type: "text", Text: "test_name_with_underscores and 2 * 3 = 6"}},
+		`\[sig-network\] \| \{x\}`:                 {{Type: "text", Text: "[sig-network] | {x}"}},
+		"":                                         nil,
+	}
+
+	for input, expected := range testCases {
+		if nodes := parseWikiInline(input); !reflect.DeepEqual(nodes, expected) {
+			t.Fatalf("unexpected nodes for '%s': %+v", input, nodes)
+		}
+	}
+}
+
+func TestParseWikiTableRow(t *testing.T) {
+	row := parseWikiTableRow(`|| Name || Status |`)
+	if len(row.Content) != 2 || row.Content[0].Type != "tableHeader" || row.Content[1].Type != "tableHeader" {
+		t.Fatalf("expected 2 header cells, got %+v", row.Content)
+	}
+
+	row = parseWikiTableRow(`| a \| b | | *5* |`)
+	if len(row.Content) != 3 || row.Content[0].Type != "tableCell" {
+		t.Fatalf("expected 3 regular cells, got %+v", row.Content)
+	}
+
+	if text := row.Content[0].Content[0].Content[0].Text; text != "a | b" {
+		t.Fatalf("expected escaped separators to be kept in the cell, got '%s'", text)
+	}
+
+	if content := row.Content[1].Content[0].Content; content != nil {
+		t.Fatalf("expected the second cell to be empty, got %+v", content)
+	}
+}
+
+func TestRenderADF(t *testing.T) {
+	report := createUploaderTestReport("EXAMPLE-15")
+	data := struct {
+		AggregateReport
+		Metadata []MetadataEntry
+	}{report, []MetadataEntry{{Key: "CI", Value: "True"}}}
+
+	buf, err := RenderEmbeddedTemplate("templates/jira_subtask_desc.tmpl", data)
+	if err != nil {
+		t.Fatalf("description template could not be rendered: %s", err)
+	}
+
+	doc, err := renderADF(buf.String())
+	if err != nil {
+		t.Fatalf("renderADF failed: %s", err)
+	}
+
+	if !isADFDocument(string(doc)) {
+		t.Fatalf("expected an ADF document, got %s", doc)
+	}
+
+	var root adfNode
+	if err := json.Unmarshal(doc, &root); err != nil {
+		t.Fatalf("ADF document could not be decoded: %s", err)
+	}
+
+	var types []string
+	for _, node := range root.Content {
+		types = append(types, node.Type)
+	}
+
+	expected := []string{"panel", "heading", "table", "heading", "table", "heading", "table", "heading", "table"}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("expected blocks %v, got %v", expected, types)
+	}
+
+	if title := root.Content[0].Content[0].Content[0]; title.Text != "Important note" || title.Marks[0].Type != "strong" {
+		t.Fatalf("expected the panel to start with its title, got %+v", title)
+	}
+
+	summary := root.Content[2]
+	if len(summary.Content) != 6 || summary.Content[0].Content[0].Type != "tableHeader" {
+		t.Fatalf("expected the summary table to have a header and 5 rows, got %+v", summary.Content)
+	}
+
+	failed := root.Content[6].Content[1]
+	if text := failed.Content[4].Content[0].Content[0].Text; text != "timed out" {
+		t.Fatalf("expected the reason of the failed test case in the last column, got '%s'", text)
+	}
+
+	code, _ := renderADF("h1. Reason\n{noformat}\n*not strong* | x\n  indented\n{noformat}\nafter")
+	if expected := `{"type":"doc","version":1,"content":[{"type":"heading","attrs":{"level":1},"content":[{"type":"text","text":"Reason"}]},` +
+		`{"type":"codeBlock","content":[{"type":"text","text":"*not strong* | x\n  indented"}]},{"type":"paragraph","content":[{"type":"text","text":"after"}]}]}`; string(code) != expected {
+		t.Fatalf("unexpected ADF document for a code block: %s", code)
+	}
+
+	if empty, _ := renderADF(""); string(empty) != `{"type":"doc","version":1,"content":[{"type":"paragraph"}]}` {
+		t.Fatalf("unexpected ADF document for an empty description: %s", empty)
+	}
+}
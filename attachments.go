@@ -0,0 +1,195 @@
+package reporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	jiraAttachmentsEndpoint      = "/rest/api/2/attachment/"
+	jiraCloudAttachmentsEndpoint = "/rest/api/3/attachment/"
+)
+
+// Attachment represents a file uploaded to the Jira issue with test results.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// JiraAttachment represents a file attached to an Issue as returned by the Jira REST API.
+type JiraAttachment struct {
+	ID       string
+	Filename string
+	AuthorID string
+}
+
+type apiResponseAttachment struct {
+	ID       string  `json:"id"`
+	Filename string  `json:"filename"`
+	Author   apiUser `json:"author"`
+}
+
+// LoadTestReportAttachments reads the test reports given as input, so that they can be attached to Jira issues.
+// Archives and compressed files are attached as they are, and the test report read from stdin is named "stdin".
+// If a bundle name is given, all test reports are bundled into a single tar.gz archive with that name instead.
+func LoadTestReportAttachments(paths []string, bundleName string) ([]Attachment, error) {
+	var attachments []Attachment
+
+	for _, p := range paths {
+		var attachment Attachment
+		var err error
+
+		if p == StdinPath {
+			attachment.Name = "stdin"
+			attachment.Data, err = readStdin()
+		} else {
+			attachment.Name = attachmentPath(p)
+			attachment.Data, err = os.ReadFile(p)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("test report '%s' could not be read: %w", p, err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if bundleName == "" {
+		for i := range attachments {
+			attachments[i].Name = filepath.Base(attachments[i].Name)
+		}
+		return attachments, nil
+	}
+
+	bundle, err := bundleAttachments(bundleName, attachments)
+	if err != nil {
+		return nil, fmt.Errorf("test reports could not be bundled: %w", err)
+	}
+
+	return []Attachment{bundle}, nil
+}
+
+// attachmentPath turns the path of a test report into a relative path used in bundles,
+// so that test reports with the same name in different directories are kept apart.
+func attachmentPath(p string) string {
+	p = filepath.ToSlash(filepath.Clean(p))
+	for {
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(p, "/"), "../")
+		if trimmed == p {
+			return p
+		}
+		p = trimmed
+	}
+}
+
+// bundleAttachments packs a set of attachments into a single tar.gz archive.
+func bundleAttachments(name string, attachments []Attachment) (Attachment, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	now := time.Now()
+	for _, attachment := range attachments {
+		header := &tar.Header{
+			Name:    attachment.Name,
+			Mode:    0o644,
+			Size:    int64(len(attachment.Data)),
+			ModTime: now,
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return Attachment{}, err
+		}
+
+		if _, err := tw.Write(attachment.Data); err != nil {
+			return Attachment{}, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return Attachment{}, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return Attachment{}, err
+	}
+
+	return Attachment{Name: name, Data: buf.Bytes()}, nil
+}
+
+// AddAttachment sends a request to Jira REST API to attach a file to an Issue with the given ID.
+func (c JiraClient) AddAttachment(ctx context.Context, id string, attachment Attachment) error {
+	infoLog(ctx).Printf("Attaching '%s' to Jira issue '%s'", attachment.Name, id)
+
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id, "attachments")
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", attachment.Name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := part.Write(attachment.Data); err != nil {
+		return err
+	}
+
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	// Jira rejects multipart requests without this header to prevent XSRF attacks
+	header := http.Header{}
+	header.Set("Content-Type", mw.FormDataContentType())
+	header.Set("X-Atlassian-Token", "no-check")
+
+	resp, err := c.sendRequestWithHeader(ctx, "POST", endpointURL, body.Bytes(), header)
+	if err != nil {
+		return fmt.Errorf("file %s could not be attached to issue %s. Reason: %w", attachment.Name, id, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("file %s could not be attached to issue %s. HTTP status code: %d", attachment.Name, id, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// DeleteAttachment sends a request to Jira REST API to delete an attachment with the given ID.
+func (c JiraClient) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	infoLog(ctx).Printf("Deleting Jira attachment '%s'", attachmentID)
+
+	endpoint := jiraAttachmentsEndpoint
+	if c.Flavor == JiraFlavorCloud {
+		endpoint = jiraCloudAttachmentsEndpoint
+	}
+
+	endpointURL, err := url.JoinPath(c.ServerURL, endpoint, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.sendRequest(ctx, "DELETE", endpointURL, nil)
+	if err != nil {
+		return fmt.Errorf("attachment %s could not be deleted. Reason: %w", attachmentID, err)
+	}
+
+	// An attachment deleted by a previous attempt of a retried request is already gone
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("attachment %s could not be deleted. HTTP status code: %d", attachmentID, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package reporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTestReportAttachments(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/report.xml", "b/report.xml"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755)
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644)
+	}
+
+	originalStdin := stdin
+	stdin = strings.NewReader("<testsuites/>")
+	t.Cleanup(func() { stdin = originalStdin })
+
+	paths := []string{filepath.Join(dir, "a/report.xml"), filepath.Join(dir, "b/report.xml"), StdinPath}
+
+	attachments, err := LoadTestReportAttachments(paths, "")
+	if err != nil {
+		t.Fatalf("LoadTestReportAttachments failed: %s", err)
+	}
+
+	expected := []Attachment{
+		{Name: "report.xml", Data: []byte("a/report.xml")},
+		{Name: "report.xml", Data: []byte("b/report.xml")},
+		{Name: "stdin", Data: []byte("<testsuites/>")},
+	}
+	if !reflect.DeepEqual(attachments, expected) {
+		t.Fatalf("unexpected attachments: %+v", attachments)
+	}
+
+	// Stdin can be read again after the test reports have been processed
+	attachments, err = LoadTestReportAttachments(paths, "test-reports.tar.gz")
+	if err != nil {
+		t.Fatalf("LoadTestReportAttachments failed: %s", err)
+	}
+
+	if len(attachments) != 1 || attachments[0].Name != "test-reports.tar.gz" {
+		t.Fatalf("expected a single bundle, got %+v", attachments)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(attachments[0].Data))
+	if err != nil {
+		t.Fatalf("bundle could not be decompressed: %s", err)
+	}
+
+	members := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("bundle could not be read: %s", err)
+		}
+
+		data, _ := io.ReadAll(tr)
+		members[header.Name] = string(data)
+	}
+
+	root := attachmentPath(dir)
+	expectedMembers := map[string]string{
+		root + "/a/report.xml": "a/report.xml",
+		root + "/b/report.xml": "b/report.xml",
+		"stdin":                "<testsuites/>",
+	}
+	if !reflect.DeepEqual(members, expectedMembers) {
+		t.Fatalf("unexpected members of the bundle: %v", members)
+	}
+}
+
+func TestAttachmentPath(t *testing.T) {
+	testCases := map[string]string{
+		"report.xml":            "report.xml",
+		"./results/report.xml":  "results/report.xml",
+		"../../results/a.xml":   "results/a.xml",
+		"/tmp/results/a.xml":    "tmp/results/a.xml",
+		"results/../report.xml": "report.xml",
+	}
+
+	for input, expected := range testCases {
+		if output := attachmentPath(input); output != expected {
+			t.Fatalf("expected '%s' for '%s', got '%s'", expected, input, output)
+		}
+	}
+}
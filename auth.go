@@ -0,0 +1,300 @@
+package reporter
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JiraAuthScheme defines how requests sent to the Jira REST API are authenticated.
+type JiraAuthScheme string
+
+const (
+	// JiraAuthBearer authenticates requests with a Personal Access Token.
+	JiraAuthBearer JiraAuthScheme = "bearer"
+	// JiraAuthBasic authenticates requests with a username and a password or an API token.
+	JiraAuthBasic JiraAuthScheme = "basic"
+	// JiraAuthOAuth1 signs requests with OAuth 1.0a (RSA-SHA1), as used by Jira Data Center application links.
+	JiraAuthOAuth1 JiraAuthScheme = "oauth1"
+	// JiraAuthClientCertificate authenticates the connection with the client certificate of the TLS config (mTLS).
+	JiraAuthClientCertificate JiraAuthScheme = "mtls"
+)
+
+// JiraAuthenticator adds credentials to requests sent to the Jira REST API.
+type JiraAuthenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// tlsAuthenticator is implemented by authenticators that rely on the client certificate presented by the transport
+// of the client instead of adding credentials to requests.
+type tlsAuthenticator interface {
+	usesClientCertificate()
+}
+
+// BearerTokenAuth authenticates requests with a Personal Access Token.
+type BearerTokenAuth struct {
+	Token string
+}
+
+func (a BearerTokenAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// BasicAuth authenticates requests with a username and a password. Jira Cloud expects
+// the email of the account as the username and an API token as the password.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// OAuth1Auth signs requests with OAuth 1.0a using the RSA-SHA1 signature method.
+// The access token has to be obtained beforehand with the OAuth dance of the Jira application link.
+type OAuth1Auth struct {
+	ConsumerKey string
+	AccessToken string
+	PrivateKey  *rsa.PrivateKey
+}
+
+func (a OAuth1Auth) Authenticate(req *http.Request) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"oauth_consumer_key":     a.ConsumerKey,
+		"oauth_token":            a.AccessToken,
+		"oauth_signature_method": "RSA-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_version":          "1.0",
+	}
+
+	hashed := sha1.Sum([]byte(oauth1SignatureBase(req.Method, req.URL, params)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA1, hashed[:])
+	if err != nil {
+		return fmt.Errorf("request could not be signed: %w", err)
+	}
+	params["oauth_signature"] = base64.StdEncoding.EncodeToString(signature)
+
+	var fields []string
+	for key, value := range params {
+		fields = append(fields, fmt.Sprintf(`%s="%s"`, oauth1Escape(key), oauth1Escape(value)))
+	}
+	slices.Sort(fields)
+	req.Header.Set("Authorization", "OAuth "+strings.Join(fields, ", "))
+
+	return nil
+}
+
+// oauth1Escape percent-encodes a string as required by RFC 5849.
+func oauth1Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// oauth1SignatureBase builds the string signed by OAuth 1.0a from the request method, the URL without
+// the query and the sorted query and OAuth parameters (RFC 5849, section 3.4.1).
+func oauth1SignatureBase(method string, u *url.URL, oauthParams map[string]string) string {
+	var params []string
+	for key, values := range u.Query() {
+		for _, value := range values {
+			params = append(params, oauth1Escape(key)+"="+oauth1Escape(value))
+		}
+	}
+	for key, value := range oauthParams {
+		params = append(params, oauth1Escape(key)+"="+oauth1Escape(value))
+	}
+	slices.Sort(params)
+
+	host := strings.ToLower(u.Host)
+	if (u.Scheme == "https" && u.Port() == "443") || (u.Scheme == "http" && u.Port() == "80") {
+		host = strings.ToLower(u.Hostname())
+	}
+	baseURL := fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), host, u.EscapedPath())
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		oauth1Escape(baseURL),
+		oauth1Escape(strings.Join(params, "&")),
+	}, "&")
+}
+
+// ClientCertificateAuth authenticates the TLS connection with the client certificate set with tls.certFile
+// and tls.keyFile (mTLS). Requests are sent without any additional credentials.
+type ClientCertificateAuth struct{}
+
+func (a ClientCertificateAuth) Authenticate(_ *http.Request) error {
+	return nil
+}
+
+func (a ClientCertificateAuth) usesClientCertificate() {}
+
+// Resolve returns the value of the secret. A value set directly takes precedence over a value read
+// from a file, which takes precedence over a value read from an environment variable.
+func (s SecretConfig) Resolve() (string, error) {
+	if s.Value != "" {
+		return s.Value, nil
+	}
+
+	if s.File != "" {
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("secret could not be read: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if s.Env != "" {
+		return os.Getenv(s.Env), nil
+	}
+
+	return "", nil
+}
+
+// resolveRequired returns the value of a secret that has to be set.
+func (s SecretConfig) resolveRequired(name string) (string, error) {
+	value, err := s.Resolve()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	if value == "" {
+		var sources []string
+		if s.File != "" {
+			sources = append(sources, fmt.Sprintf("file '%s'", s.File))
+		}
+		if s.Env != "" {
+			sources = append(sources, fmt.Sprintf("env var '%s'", s.Env))
+		}
+
+		if len(sources) == 0 {
+			return "", fmt.Errorf("%s is not set", name)
+		}
+		return "", fmt.Errorf("%s is not set (checked %s)", name, strings.Join(sources, ", "))
+	}
+
+	return value, nil
+}
+
+// NewJiraAuthenticator creates an authenticator for the scheme selected in the server config, resolving
+// all required secrets. If no scheme is selected, Jira Data Center requests are authenticated with a
+// Personal Access Token, and Jira Cloud requests with the email of the account and an API token.
+func NewJiraAuthenticator(config JiraServerConfig) (JiraAuthenticator, error) {
+	auth := config.Auth
+
+	scheme := auth.Scheme
+	if scheme == "" {
+		scheme = JiraAuthBearer
+		if config.Flavor == JiraFlavorCloud {
+			scheme = JiraAuthBasic
+		}
+	}
+
+	switch scheme {
+	case JiraAuthBearer:
+		token, err := auth.Token.resolveRequired("auth.token")
+		if err != nil {
+			return nil, err
+		}
+		return BearerTokenAuth{Token: token}, nil
+
+	case JiraAuthBasic:
+		username, err := auth.Username.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("auth.username: %w", err)
+		}
+		if username == "" {
+			username = config.Email
+		}
+		if username == "" {
+			return nil, errors.New("auth.username is not set. For Jira Cloud, set the email of the account instead")
+		}
+
+		// API tokens are used as passwords, so the token is used if no password has been set
+		password, err := auth.Password.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("auth.password: %w", err)
+		}
+		if password == "" {
+			if password, err = auth.Token.resolveRequired("auth.password (or auth.token)"); err != nil {
+				return nil, err
+			}
+		}
+		return BasicAuth{Username: username, Password: password}, nil
+
+	case JiraAuthOAuth1:
+		consumerKey, err := auth.OAuth1.ConsumerKey.resolveRequired("auth.oauth1.consumerKey")
+		if err != nil {
+			return nil, err
+		}
+
+		accessToken, err := auth.OAuth1.AccessToken.resolveRequired("auth.oauth1.accessToken")
+		if err != nil {
+			return nil, err
+		}
+
+		privateKeyPEM, err := auth.OAuth1.PrivateKey.resolveRequired("auth.oauth1.privateKey")
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err := parseRSAPrivateKey([]byte(privateKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("auth.oauth1.privateKey: %w", err)
+		}
+		return OAuth1Auth{ConsumerKey: consumerKey, AccessToken: accessToken, PrivateKey: privateKey}, nil
+
+	case JiraAuthClientCertificate:
+		// The certificate is loaded with the rest of the TLS config, so that it is only configured in one place
+		if config.TLS.CertFile == "" || config.TLS.KeyFile == "" {
+			return nil, errors.New("the mtls auth scheme requires a client certificate set with tls.certFile and tls.keyFile")
+		}
+		return ClientCertificateAuth{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown auth scheme '%s'. Expected one of: %s, %s, %s, %s",
+		scheme, JiraAuthBearer, JiraAuthBasic, JiraAuthOAuth1, JiraAuthClientCertificate)
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM-encoded private key found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
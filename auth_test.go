@@ -0,0 +1,209 @@
+package reporter
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretConfigResolve(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("REPORTER_TEST_SECRET", "from-env")
+
+	testCases := []struct {
+		secret   SecretConfig
+		expected string
+	}{
+		{SecretConfig{Value: "from-value", File: file, Env: "REPORTER_TEST_SECRET"}, "from-value"},
+		{SecretConfig{File: file, Env: "REPORTER_TEST_SECRET"}, "from-file"},
+		{SecretConfig{Env: "REPORTER_TEST_SECRET"}, "from-env"},
+		{SecretConfig{}, ""},
+	}
+
+	for _, tc := range testCases {
+		value, err := tc.secret.Resolve()
+		if err != nil || value != tc.expected {
+			t.Fatalf("expected '%s' for %+v, got '%s' (%v)", tc.expected, tc.secret, value, err)
+		}
+	}
+
+	if _, err := (SecretConfig{File: filepath.Join(t.TempDir(), "missing")}).Resolve(); err == nil {
+		t.Fatalf("expected an error for a missing secret file")
+	}
+}
+
+func TestNewJiraAuthenticator(t *testing.T) {
+	t.Setenv("REPORTER_TEST_TOKEN", "")
+
+	testCases := []struct {
+		config   JiraServerConfig
+		expected JiraAuthenticator
+		err      string
+	}{{
+		config:   JiraServerConfig{Auth: JiraServerAuthConfig{Token: SecretConfig{Value: "token"}}},
+		expected: BearerTokenAuth{Token: "token"},
+	}, {
+		config: JiraServerConfig{
+			Flavor: JiraFlavorCloud,
+			Email:  "user@example.com",
+			Auth:   JiraServerAuthConfig{Token: SecretConfig{Value: "api-token"}},
+		},
+		expected: BasicAuth{Username: "user@example.com", Password: "api-token"},
+	}, {
+		config: JiraServerConfig{Auth: JiraServerAuthConfig{
+			Scheme:   JiraAuthBasic,
+			Username: SecretConfig{Value: "user"},
+			Password: SecretConfig{Value: "password"},
+		}},
+		expected: BasicAuth{Username: "user", Password: "password"},
+	}, {
+		config: JiraServerConfig{Auth: JiraServerAuthConfig{Token: SecretConfig{Env: "REPORTER_TEST_TOKEN"}}},
+		err:    "auth.token is not set (checked env var 'REPORTER_TEST_TOKEN')",
+	}, {
+		config: JiraServerConfig{Auth: JiraServerAuthConfig{Scheme: JiraAuthBasic, Token: SecretConfig{Value: "token"}}},
+		err:    "auth.username is not set",
+	}, {
+		config: JiraServerConfig{Auth: JiraServerAuthConfig{Scheme: JiraAuthOAuth1}},
+		err:    "auth.oauth1.consumerKey is not set",
+	}, {
+		config: JiraServerConfig{
+			Auth: JiraServerAuthConfig{Scheme: JiraAuthClientCertificate},
+			TLS:  JiraServerTLSConfig{CertFile: "client.crt", KeyFile: "client.key"},
+		},
+		expected: ClientCertificateAuth{},
+	}, {
+		config: JiraServerConfig{Auth: JiraServerAuthConfig{Scheme: JiraAuthClientCertificate}},
+		err:    "the mtls auth scheme requires a client certificate",
+	}, {
+		config: JiraServerConfig{Auth: JiraServerAuthConfig{Scheme: "kerberos"}},
+		err:    "unknown auth scheme 'kerberos'",
+	}}
+
+	for _, tc := range testCases {
+		auth, err := NewJiraAuthenticator(tc.config)
+		if tc.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Fatalf("expected error '%s', got %v", tc.err, err)
+			}
+			continue
+		}
+
+		if err != nil || auth != tc.expected {
+			t.Fatalf("expected %+v, got %+v (%v)", tc.expected, auth, err)
+		}
+	}
+}
+
+func TestOAuth1Auth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	auth, err := NewJiraAuthenticator(JiraServerConfig{Auth: JiraServerAuthConfig{
+		Scheme: JiraAuthOAuth1,
+		OAuth1: JiraServerOAuth1Config{
+			ConsumerKey: SecretConfig{Value: "reporter"},
+			AccessToken: SecretConfig{Value: "access token"},
+			PrivateKey:  SecretConfig{Value: string(keyPEM)},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("NewJiraAuthenticator failed: %s", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://jira.example.com:443/rest/api/2/issue/EXAMPLE-15?fields=summary", nil)
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Authenticate failed: %s", err)
+	}
+
+	header, ok := strings.CutPrefix(req.Header.Get("Authorization"), "OAuth ")
+	if !ok {
+		t.Fatalf("expected an OAuth Authorization header, got '%s'", req.Header.Get("Authorization"))
+	}
+
+	params := map[string]string{}
+	for _, field := range strings.Split(header, ", ") {
+		key, value, _ := strings.Cut(field, "=")
+		params[key], _ = url.QueryUnescape(strings.Trim(value, `"`))
+	}
+
+	if params["oauth_token"] != "access token" || params["oauth_consumer_key"] != "reporter" {
+		t.Fatalf("unexpected OAuth parameters: %v", params)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["oauth_signature"])
+	if err != nil {
+		t.Fatalf("signature could not be decoded: %s", err)
+	}
+	delete(params, "oauth_signature")
+
+	base := oauth1SignatureBase(req.Method, req.URL, params)
+	if !strings.HasPrefix(base, "GET&https%3A%2F%2Fjira.example.com%2Frest%2Fapi%2F2%2Fissue%2FEXAMPLE-15&fields%3Dsummary%26") {
+		t.Fatalf("unexpected signature base string: %s", base)
+	}
+
+	hashed := sha1.Sum([]byte(base))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, hashed[:], signature); err != nil {
+		t.Fatalf("signature could not be verified: %s", err)
+	}
+}
+
+func TestClientCertificateAuth(t *testing.T) {
+	jira, server := newUnstartedFakeJira(t)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", nil, "")
+
+	// The certificate of the test server is reused as the client certificate
+	dir := t.TempDir()
+	cert := server.TLS.Certificates[0]
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	client, err := NewJiraClient(JiraServerConfig{
+		URL:  server.URL,
+		Auth: JiraServerAuthConfig{Scheme: JiraAuthClientCertificate},
+		TLS:  JiraServerTLSConfig{CAFile: writeServerCA(t, server), CertFile: certFile, KeyFile: keyFile},
+	})
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	issue, err := client.GetIssue(context.Background(), "EXAMPLE-15")
+	if err != nil || issue.Summary != "QE Story" {
+		t.Fatalf("expected the issue to be fetched with the client certificate, got %+v (%v)", issue, err)
+	}
+
+	if jira.authorization != "" {
+		t.Fatalf("expected no Authorization header, got '%s'", jira.authorization)
+	}
+
+	// The client certificate is part of the default transport, so it cannot be combined with a custom client
+	client.HTTPClient = &http.Client{}
+	if _, err := client.GetIssue(context.Background(), "EXAMPLE-15"); err == nil {
+		t.Fatal("expected an error when using client certificate authentication with a custom HTTP client")
+	}
+}
@@ -0,0 +1,252 @@
+package reporter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/joshdk/go-junit"
+)
+
+const (
+	// reporterBugLabel is set on all Bugs filed by Reporter, so that open Bugs can be found with a single query.
+	reporterBugLabel = "reporter-bug"
+	// testCaseBugLabelPrefix starts the label identifying the Test Case a Bug has been filed for.
+	testCaseBugLabelPrefix = "reporter-test-"
+	// bugPassedComment is posted to open Bugs once their Test Case passes again.
+	bugPassedComment = "✔️ The test case passed again"
+
+	maxSummaryLength = 255
+)
+
+// BugOnPassAction defines what happens to an open Bug once its Test Case passes again.
+type BugOnPassAction string
+
+const (
+	// BugOnPassNone leaves the Bug untouched.
+	BugOnPassNone BugOnPassAction = "none"
+	// BugOnPassComment posts a comment to the Bug.
+	BugOnPassComment BugOnPassAction = "comment"
+	// BugOnPassClose posts a comment to the Bug and closes it with the configured transition.
+	BugOnPassClose BugOnPassAction = "close"
+)
+
+// Validate checks whether the action is one of the supported actions.
+func (a BugOnPassAction) Validate() error {
+	switch a {
+	case "", BugOnPassNone, BugOnPassComment, BugOnPassClose:
+		return nil
+	}
+
+	return fmt.Errorf("unknown action '%s'. Expected one of: %s, %s, %s", a, BugOnPassNone, BugOnPassComment, BugOnPassClose)
+}
+
+// testCaseBugLabel returns the label identifying the Bug filed for a Test Case. The label is derived
+// from the names of the Test Suite and the Test Case, so it stays the same across runs.
+func testCaseBugLabel(suite string, test TestCase) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{suite, test.Classname, test.Name}, "\x00")))
+	return testCaseBugLabelPrefix + hex.EncodeToString(hash[:])[:12]
+}
+
+func truncateSummary(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxSummaryLength {
+		return s
+	}
+
+	return string(runes[:maxSummaryLength-1]) + "…"
+}
+
+type bugRegistryKey struct{}
+
+// bugRegistry keeps track of the Bugs filed while uploading a set of reports. Reports uploaded concurrently
+// file the Bugs of the same project one at a time, so that a Test Case routed to multiple destinations
+// gets a single Bug.
+type bugRegistry struct {
+	mu       sync.Mutex
+	projects map[string]*projectBugs
+}
+
+type projectBugs struct {
+	// Held while the Bugs of the project are being looked up and filed
+	mu sync.Mutex
+	// IDs of the Bugs filed so far, keyed by the labels of their Test Cases
+	filed map[string]string
+}
+
+// withBugRegistry returns a context, in which all Bugs filed by fileBugsInJira are recorded in a given registry.
+func withBugRegistry(ctx context.Context, registry *bugRegistry) context.Context {
+	return context.WithValue(ctx, bugRegistryKey{}, registry)
+}
+
+// projectBugsFor returns the Bugs filed in a given project so far, using the registry of the context if there is one.
+func projectBugsFor(ctx context.Context, project string) *projectBugs {
+	registry, ok := ctx.Value(bugRegistryKey{}).(*bugRegistry)
+	if !ok {
+		return &projectBugs{filed: map[string]string{}}
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.projects == nil {
+		registry.projects = map[string]*projectBugs{}
+	}
+
+	p, ok := registry.projects[project]
+	if !ok {
+		p = &projectBugs{filed: map[string]string{}}
+		registry.projects[project] = p
+	}
+
+	return p
+}
+
+// fileBugsInJira files a Bug for each failed Test Case of a report that does not have an open Bug yet, and links
+// it to the destination of the report. If configured, open Bugs of Test Cases that passed again are commented
+// on or closed. Open Bugs are found by the labels set by Reporter.
+func fileBugsInJira(ctx context.Context, api JiraAPI, config Config, report AggregateReport, metadata []MetadataEntry) error {
+	bugs := config.Spec.Jira.DesiredState.Bugs
+
+	client, err := jiraCapability[JiraSearchAPI](api, "Bugs")
+	if err != nil {
+		return err
+	}
+
+	project := bugs.Project
+	if project == "" {
+		project = getProjectFromParentIssueID(report.Destination)
+	}
+
+	known := projectBugsFor(ctx, project)
+	known.mu.Lock()
+	defer known.mu.Unlock()
+
+	jql := fmt.Sprintf("project = %s AND labels = %s AND statusCategory != Done", jqlQuote(project), jqlQuote(reporterBugLabel))
+	issues, err := client.SearchIssues(ctx, jql)
+	if err != nil {
+		return fmt.Errorf("open Bugs could not be fetched: %w", err)
+	}
+
+	openBugs := map[string]string{}
+	for _, issue := range issues {
+		for _, label := range issue.Labels {
+			if strings.HasPrefix(label, testCaseBugLabelPrefix) {
+				openBugs[label] = issue.ID
+			}
+		}
+	}
+
+	// Bugs filed by other reports may not be returned by the search yet
+	for label, id := range known.filed {
+		openBugs[label] = id
+	}
+
+	filed := 0
+	failed := map[string]bool{}
+	for _, test := range report.FailedTestCases() {
+		label := testCaseBugLabel(test.TestSuite, test.TestCase)
+		failed[label] = true
+		if id, ok := openBugs[label]; ok {
+			infoLog(ctx).Printf("Found open Bug '%s' for failed test case '%s'", id, test.Name)
+			continue
+		}
+
+		if bugs.MaxPerReport > 0 && filed >= bugs.MaxPerReport {
+			warnLog(ctx).Printf("Only %d Bugs are filed for a single Aggregate Report. Skipping the remaining failed test cases", bugs.MaxPerReport)
+			break
+		}
+
+		data := struct {
+			Destination string
+			Label       string
+			FailedTestCase
+			Metadata []MetadataEntry
+		}{report.Destination, label, test, metadata}
+
+		buf, err := renderConfiguredTemplate("bug description", bugs.TemplatePath, data)
+		if err != nil {
+			return err
+		}
+
+		summary := truncateSummary(fmt.Sprintf("%s%s: %s", bugs.SummaryPrefix, test.TestSuite, test.Name))
+		labels := append([]string{reporterBugLabel, label}, bugs.Labels...)
+
+		id, err := client.CreateIssue(ctx, project, bugs.IssueType, summary, buf.String(), labels)
+		if err != nil {
+			return fmt.Errorf("bug could not be filed for test case '%s': %w", test.Name, err)
+		}
+		infoLog(ctx).Printf("Filed Bug '%s' for failed test case '%s'", id, test.Name)
+
+		openBugs[label] = id
+		known.filed[label] = id
+		filed++
+
+		if err := client.LinkIssues(ctx, bugs.LinkType, id, report.Destination); err != nil {
+			return err
+		}
+	}
+
+	if bugs.OnPass == "" || bugs.OnPass == BugOnPassNone {
+		return nil
+	}
+
+	for _, suite := range report.TestSuites {
+		for _, test := range suite.TestCases {
+			label := testCaseBugLabel(suite.Name, test)
+			id, ok := openBugs[label]
+			if !ok || test.Status != junit.StatusPassed {
+				continue
+			}
+
+			// Test Cases that also failed in the report (e.g. when rerun) did not pass again, and Bugs
+			// filed during this upload are never resolved by it
+			if _, filedNow := known.filed[label]; failed[label] || filedNow {
+				continue
+			}
+			// Each open Bug is handled only once, even if its Test Case was found multiple times
+			delete(openBugs, label)
+
+			if err := resolveBugInJira(ctx, api, bugs, id, report.Destination); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveBugInJira comments on an open Bug whose Test Case passed again and, if configured, closes it.
+// The comment is not repeated if the last comment of the Bug already reports that the Test Case passed.
+func resolveBugInJira(ctx context.Context, api JiraAPI, bugs JiraIssueDesiredStateBugsConfig, id string, destination string) error {
+	client, err := jiraCapability[JiraCommentsAPI](api, "comments on Bugs")
+	if err != nil {
+		return err
+	}
+
+	comments, err := client.GetComments(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(comments) == 0 || !strings.Contains(comments[len(comments)-1].Body, bugPassedComment) {
+		comment := fmt.Sprintf("%s in the run reported to %s.", bugPassedComment, destination)
+		if err := client.AddComment(ctx, id, comment); err != nil {
+			return err
+		}
+	}
+
+	if bugs.OnPass == BugOnPassClose {
+		transitions, err := jiraCapability[JiraTransitionsAPI](api, "Bug transitions")
+		if err != nil {
+			return err
+		}
+
+		return transitionInJira(ctx, transitions, id, bugs.CloseTransition)
+	}
+
+	return nil
+}
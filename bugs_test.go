@@ -0,0 +1,234 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFileBugsInJira(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+
+	report := createUploaderTestReport("EXAMPLE-20")
+	report.TestSuites[0].TestCases = append(report.TestSuites[0].TestCases, TestCase{Name: "three", Status: "error"})
+	report.TestSuites[0].Counts = Counts{Passed: 1, Failed: 1, Errored: 1, Total: 3}
+	report.AggregateCounts()
+	suite := report.TestSuites[0]
+
+	// Open Bugs of the failed test case "three" and the passed test case "one", and a closed Bug of "two"
+	jira.addIssue("EXAMPLE-30", "Bug", "Networking: three", []string{reporterBugLabel, testCaseBugLabel("Networking", suite.TestCases[2])}, "")
+	jira.addIssue("EXAMPLE-31", "Bug", "Networking: one", []string{reporterBugLabel, testCaseBugLabel("Networking", suite.TestCases[0])}, "")
+	jira.addIssue("EXAMPLE-32", "Bug", "Networking: two", []string{reporterBugLabel, testCaseBugLabel("Networking", suite.TestCases[1])}, "")
+	jira.issue("EXAMPLE-32").Fields.Status.Name = "Done"
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.Bugs = JiraIssueDesiredStateBugsConfig{
+		Enabled:       true,
+		IssueType:     "Bug",
+		SummaryPrefix: "[CI] ",
+		TemplatePath:  "embedded:templates/jira_bug_desc.tmpl",
+		Labels:        []string{"ci-failure"},
+		LinkType:      "Relates",
+		OnPass:        BugOnPassComment,
+	}
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	for range 2 {
+		if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{report}, nil, config); err != nil {
+			t.Fatalf("UploadAggregateReports failed: %s", err)
+		}
+	}
+
+	var filed []*apiResponseIssue
+	for _, issue := range jira.issues {
+		if issue.Fields.IssueType.Name == "Bug" && !slices.Contains([]string{"EXAMPLE-30", "EXAMPLE-31", "EXAMPLE-32"}, issue.Key) {
+			filed = append(filed, issue)
+		}
+	}
+
+	if len(filed) != 1 {
+		t.Fatalf("expected a single Bug to be filed for test case 'two' across both runs, got %d", len(filed))
+	}
+
+	bug := filed[0]
+	expectedLabels := []string{reporterBugLabel, testCaseBugLabel("Networking", suite.TestCases[1]), "ci-failure"}
+	if bug.Fields.Summary != "[CI] Networking: two" || !slices.Equal(bug.Fields.Labels, expectedLabels) {
+		t.Fatalf("unexpected Bug: %+v", bug.Fields)
+	}
+
+	if !strings.Contains(string(bug.Fields.Description), "{noformat}\ntimed out\n{noformat}") {
+		t.Fatalf("expected the description to contain the failure message, got:\n%s", bug.Fields.Description)
+	}
+
+	if len(jira.links) != 1 || jira.links[0] != "Relates "+bug.Key+" EXAMPLE-20" {
+		t.Fatalf("expected the Bug to be linked to the destination, got %v", jira.links)
+	}
+
+	// The passed test case is commented on only once
+	if comments := jira.comments["EXAMPLE-31"]; len(comments) != 1 || !strings.Contains(string(comments[0].Body), "passed again") {
+		t.Fatalf("expected a single comment on the Bug of the passed test case, got %+v", comments)
+	}
+
+	config.Spec.Jira.DesiredState.Bugs.OnPass = BugOnPassClose
+	config.Spec.Jira.DesiredState.Bugs.CloseTransition = "Done"
+	if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{report}, nil, config); err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	if status := jira.issue("EXAMPLE-31").Fields.Status.Name; status != "Done" || len(jira.comments["EXAMPLE-31"]) != 1 {
+		t.Fatalf("expected the Bug of the passed test case to be closed without another comment, got '%s'", status)
+	}
+
+	if status := jira.issue("EXAMPLE-30").Fields.Status.Name; status != "To Do" {
+		t.Fatalf("expected the Bug of the failed test case to stay open, got '%s'", status)
+	}
+}
+
+func TestFileBugsInJiraLimit(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", nil, "")
+
+	report := createUploaderTestReport("EXAMPLE-15")
+	for _, name := range []string{"three", "four"} {
+		report.TestSuites[0].TestCases = append(report.TestSuites[0].TestCases, TestCase{Name: name, Status: "failed"})
+	}
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.Bugs = JiraIssueDesiredStateBugsConfig{
+		Enabled:      true,
+		IssueType:    "Bug",
+		TemplatePath: "embedded:templates/jira_bug_desc.tmpl",
+		LinkType:     "Relates",
+		MaxPerReport: 2,
+	}
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	if err := fileBugsInJira(context.Background(), client, config, report, nil); err != nil {
+		t.Fatalf("fileBugsInJira failed: %s", err)
+	}
+
+	if len(jira.links) != 2 {
+		t.Fatalf("expected only 2 Bugs to be filed, got %v", jira.links)
+	}
+}
+
+func TestFileBugsInJiraWithReruns(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", nil, "")
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+
+	// Without deduplication, a rerun test case is reported both as failed and as passed
+	rerun := createUploaderTestReport("EXAMPLE-15")
+	rerun.TestSuites[0].TestCases = append(rerun.TestSuites[0].TestCases, TestCase{Name: "two", Status: "passed"})
+	rerun.TestSuites[0].Counts = Counts{Passed: 2, Failed: 1, Total: 3}
+	rerun.AggregateCounts()
+
+	// The same test case passed in another report uploaded at the same time
+	passed := createUploaderTestReport("EXAMPLE-20")
+	passed.TestSuites[0].TestCases[1].Status = "passed"
+	passed.TestSuites[0].Counts = Counts{Passed: 2, Total: 2}
+	passed.AggregateCounts()
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.Server.MaxConcurrency = 2
+	config.Spec.Jira.DesiredState.Bugs = JiraIssueDesiredStateBugsConfig{
+		Enabled:         true,
+		IssueType:       "Bug",
+		TemplatePath:    "embedded:templates/jira_bug_desc.tmpl",
+		LinkType:        "Relates",
+		OnPass:          BugOnPassClose,
+		CloseTransition: "Done",
+	}
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{rerun, passed}, nil, config); err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	var bugs []*apiResponseIssue
+	for _, issue := range jira.issues {
+		if issue.Fields.IssueType.Name == "Bug" {
+			bugs = append(bugs, issue)
+		}
+	}
+
+	if len(bugs) != 1 || bugs[0].Fields.Status.Name != "To Do" || len(jira.comments[bugs[0].Key]) != 0 {
+		t.Fatalf("expected a single open Bug without comments, got %d Bugs: %+v", len(bugs), bugs)
+	}
+}
+
+func TestFileBugsInJiraConcurrently(t *testing.T) {
+	jira, server := newFakeJira(t)
+
+	// Overlapping routes send the same failed test case to multiple destinations in the same project
+	var reports []AggregateReport
+	for i := range 8 {
+		key := fmt.Sprintf("EXAMPLE-%d", i+1)
+		jira.addIssue(key, "Story", "QE Story", nil, "")
+		reports = append(reports, createUploaderTestReport(key))
+	}
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.Server.MaxConcurrency = 4
+	config.Spec.Jira.DesiredState.Bugs = JiraIssueDesiredStateBugsConfig{
+		Enabled:      true,
+		IssueType:    "Bug",
+		TemplatePath: "embedded:templates/jira_bug_desc.tmpl",
+		LinkType:     "Relates",
+	}
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	if _, err := UploadAggregateReports(context.Background(), client, reports, nil, config); err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	bugs := 0
+	for _, issue := range jira.issues {
+		if issue.Fields.IssueType.Name == "Bug" {
+			bugs++
+		}
+	}
+
+	if bugs != 1 || len(jira.links) != 1 {
+		t.Fatalf("expected a single Bug to be filed and linked, got %d Bugs and links %v", bugs, jira.links)
+	}
+}
+
+func TestTestCaseBugLabel(t *testing.T) {
+	label := testCaseBugLabel("Networking", TestCase{Name: "two", Classname: "net"})
+	if label != testCaseBugLabel("Networking", TestCase{Name: "two", Classname: "net", Status: "failed", Message: "timed out"}) {
+		t.Fatalf("expected the label to depend only on the names of the test suite and the test case")
+	}
+
+	if label == testCaseBugLabel("Networking", TestCase{Name: "two", Classname: "dns"}) {
+		t.Fatalf("expected test cases with different class names to have different labels")
+	}
+
+	if !strings.HasPrefix(label, testCaseBugLabelPrefix) || len(label) != len(testCaseBugLabelPrefix)+12 {
+		t.Fatalf("unexpected label: %s", label)
+	}
+
+	if err := BugOnPassAction("reopen").Validate(); err == nil {
+		t.Fatalf("expected an unknown action to be rejected")
+	}
+}
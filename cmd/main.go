@@ -30,6 +30,7 @@ var Commands = []string{"upload"}
 const (
 	EnvNamePrefix          = "REPORTER"
 	EnvNameJiraAccessToken = "REPORTER_JIRA_TOKEN"
+	EnvNameJiraEmail       = "REPORTER_JIRA_EMAIL"
 
 	ConfigName = "config"
 	ConfigType = "yaml"
@@ -37,6 +38,15 @@ const (
 	usageTemplateFile = "templates/cli_usage.tmpl"
 )
 
+// Exit codes, which let pipelines tell apart failed tests from failures of Reporter itself
+const (
+	ExitCodeSuccess      = 0
+	ExitCodeError        = 1
+	ExitCodeGateFailed   = 2
+	ExitCodeUploadFailed = 3
+	ExitCodeBadInput     = 4
+)
+
 func init() {
 	flag.BoolP("verbose", "v", false, "Enable verbose logging")
 	flag.ErrHelp = errors.New("reporter: help requested")
@@ -61,6 +71,18 @@ func PrintUsage(command string, commands []string, flagset *flag.FlagSet) {
 	fmt.Print(buf.String())
 }
 
+// Fatal logs an error and exits with a given exit code.
+func Fatal(code int, v ...any) {
+	ErrorLog.Println(v...)
+	os.Exit(code)
+}
+
+// Fatalf logs a formatted error message and exits with a given exit code.
+func Fatalf(code int, format string, v ...any) {
+	ErrorLog.Printf(format, v...)
+	os.Exit(code)
+}
+
 func LogReleaseDetails() {
 	InfoLog.Printf("Reporter version %s, commit %s", Version, CommitHash)
 }
@@ -73,8 +95,7 @@ func main() {
 
 	switch os.Args[1] {
 	case "upload":
-		UploadCmd()
-		os.Exit(0)
+		os.Exit(UploadCmd())
 
 	default:
 		flag.Parse()
@@ -2,37 +2,51 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"slices"
 	"strings"
+	"syscall"
 
 	reporter "github.com/redhat-eets/reporter"
 	flag "github.com/spf13/pflag"
 	viper "github.com/spf13/viper"
 )
 
-var UploadFlagSet = flag.NewFlagSet("upload", flag.ExitOnError)
+var UploadFlagSet = flag.NewFlagSet("upload", flag.ContinueOnError)
 
 var (
 	flagConfigPath       string
 	flagJUnitInputPaths  []string
+	flagInputFormat      string
 	flagMetadataStrings  []string
 	flagJiraDestIssueID  string
 	flagJiraServerURL    string
 	flagJiraAccessToken  string
+	flagJiraEmail        string
 	flagJiraSyncDisabled bool
+	flagParallelUploads  int
+	flagOutputFile       string
+	flagOutputFormat     string
 )
 
 const (
 	defaultConfigPath       = "."
 	defaultJUnitInputPath   = "input/"
+	defaultInputFormat      = ""
 	defaultJiraDestIssueID  = ""
 	defaultJiraServerURL    = "https://issues.redhat.com"
 	defaultJiraAccessToken  = ""
+	defaultJiraEmail        = ""
 	defaultJiraSyncDisabled = false
+	defaultParallelUploads  = 0
+	defaultOutputFile       = ""
+	defaultOutputFormat     = "json"
 )
 
 func init() {
@@ -41,7 +55,14 @@ func init() {
 		"input",
 		"i",
 		[]string{defaultJUnitInputPath},
-		"Optional path to JUnit XML test report file. Can be provided multiple times",
+		"Optional path to a test report file, an archive or a directory with test reports, or \"-\" to read from stdin. Can be provided multiple times",
+	)
+	UploadFlagSet.StringVarP(
+		&flagInputFormat,
+		"input-format",
+		"f",
+		defaultInputFormat,
+		fmt.Sprintf("Optional format of the test reports, one of %v. Detected automatically by default", reporter.InputFormats),
 	)
 	UploadFlagSet.StringVarP(
 		&flagJiraDestIssueID,
@@ -78,6 +99,13 @@ func init() {
 		defaultJiraAccessToken,
 		fmt.Sprintf("Service account access token for Jira. Can also be set using the \"%s\" env var", EnvNameJiraAccessToken),
 	)
+	UploadFlagSet.StringVarP(
+		&flagJiraEmail,
+		"jira-email",
+		"e",
+		defaultJiraEmail,
+		fmt.Sprintf("Email of the Jira Cloud account the access token belongs to. Can also be set using the \"%s\" env var", EnvNameJiraEmail),
+	)
 	UploadFlagSet.BoolVarP(
 		&flagJiraSyncDisabled,
 		"no-sync",
@@ -85,15 +113,34 @@ func init() {
 		defaultJiraSyncDisabled,
 		"Toggle to disable sending requests to the Jira API",
 	)
+	UploadFlagSet.IntVarP(
+		&flagParallelUploads,
+		"parallel",
+		"p",
+		defaultParallelUploads,
+		"Optional number of Aggregate Reports uploaded to Jira at the same time. Overrides 'spec.jira.server.maxConcurrency' in the config file",
+	)
+	UploadFlagSet.StringVarP(
+		&flagOutputFile,
+		"output-file",
+		"o",
+		defaultOutputFile,
+		"Optional path to a file the results of the upload are written to, e.g. to link the Jira Sub-tasks in later pipeline steps",
+	)
+	UploadFlagSet.StringVar(
+		&flagOutputFormat,
+		"output-format",
+		defaultOutputFormat,
+		fmt.Sprintf("Optional format of the file set with the -o/--output-file flag, one of %v", reporter.OutputFormats),
+	)
 	UploadFlagSet.Usage = func() { PrintUsage("upload", []string{}, UploadFlagSet) }
 
 	viper.BindPFlags(UploadFlagSet)
 	viper.BindEnv("jira-token", EnvNameJiraAccessToken)
+	viper.BindEnv("jira-email", EnvNameJiraEmail)
 }
 
-func isValidJUnitInputFile(path string, d fs.DirEntry, err error) bool {
-	supportedFileExtensions := []string{".junit", ".xml"}
-
+func isValidJUnitInputFile(path string, d fs.DirEntry, err error, format reporter.InputFormat) bool {
 	if err != nil {
 		return false
 	}
@@ -102,21 +149,24 @@ func isValidJUnitInputFile(path string, d fs.DirEntry, err error) bool {
 		return false
 	}
 
-	hasSupportedExtension := false
-	for _, ext := range supportedFileExtensions {
-		if strings.HasSuffix(path, ext) {
-			hasSupportedExtension = true
-		}
-	}
-
-	return hasSupportedExtension
+	// Archives are expanded later, and only the test reports found inside are processed
+	return format.IsSupportedInputFile(path)
 }
 
-func getJUnitTestReportPaths(paths []string) (files []string, err error) {
+// getJUnitTestReportPaths collects the paths of test reports. Directories are searched for files with the extensions
+// of a given input format, so that unrelated files in other formats are not loaded.
+func getJUnitTestReportPaths(paths []string, format reporter.InputFormat) (files []string, err error) {
 	for _, input := range paths {
+		if input == reporter.StdinPath {
+			if !slices.Contains(files, input) {
+				files = append(files, input)
+			}
+			continue
+		}
+
 		err := filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
 			// O(n) search performed for each valid path? Oops, sorry! Doesn't matter anyway.
-			if isValidJUnitInputFile(path, d, err) && !slices.Contains(files, path) {
+			if isValidJUnitInputFile(path, d, err, format) && !slices.Contains(files, path) {
 				files = append(files, path)
 			}
 			return nil
@@ -136,7 +186,7 @@ func getUserMetadataFromInput(input []string) (entries []reporter.MetadataEntry)
 		p := strings.Split(s, separator)
 
 		if len(p) < 2 {
-			ErrorLog.Fatalf("Received '%s' as a metadata string, expected the input to be in the 'key=value' format instead", s)
+			Fatalf(ExitCodeBadInput, "Received '%s' as a metadata string, expected the input to be in the 'key=value' format instead", s)
 		}
 
 		key := p[0]
@@ -190,6 +240,35 @@ func loadConfig(path string) (reporter.Config, error) {
 		return config, err
 	}
 
+	// Client certificates are configured in a single place, together with the rest of the TLS settings
+	if viper.IsSet("spec.jira.server.auth.clientCertificate") {
+		return config, errors.New("jira config could not be loaded: server.auth.clientCertificate: the client certificate has to be set with server.tls.certFile and server.tls.keyFile")
+	}
+
+	if err := config.Spec.Jira.Server.Flavor.Validate(); err != nil {
+		return config, fmt.Errorf("jira config could not be loaded: server.flavor: %w", err)
+	}
+
+	if config.Spec.Jira.Server.MaxConcurrency < 0 {
+		return config, fmt.Errorf("jira config could not be loaded: server.maxConcurrency: expected a non-negative number, got %d", config.Spec.Jira.Server.MaxConcurrency)
+	}
+
+	if err := config.Spec.Jira.DesiredState.Summary.Validate(); err != nil {
+		return config, fmt.Errorf("jira config could not be loaded: desiredState.summary: %w", err)
+	}
+
+	if err := config.Spec.Jira.DesiredState.Bugs.OnPass.Validate(); err != nil {
+		return config, fmt.Errorf("jira config could not be loaded: desiredState.bugs.onPass: %w", err)
+	}
+
+	if err := config.Spec.Gates.Validate(); err != nil {
+		return config, fmt.Errorf("quality gates could not be loaded: %w", err)
+	}
+
+	if err := config.Spec.Reporting.Compile(); err != nil {
+		return config, fmt.Errorf("reporting config could not be loaded: %w", err)
+	}
+
 	if config.Spec.Reporting.Routing == nil {
 		InfoLog.Println("No custom routing for test reports found. This can be configured in the 'spec.reporting.routing' section of the config file")
 	} else {
@@ -199,14 +278,26 @@ func loadConfig(path string) (reporter.Config, error) {
 	return config, nil
 }
 
-func UploadCmd() {
+// UploadCmd runs the upload command and returns its exit code. The command fails with ExitCodeGateFailed
+// if all reports have been uploaded, but any of the quality gates configured in 'spec.gates' has not been met.
+func UploadCmd() int {
 	LogReleaseDetails()
 
-	UploadFlagSet.Parse(os.Args[2:])
+	// Parsing errors are printed along with the usage by the flag set
+	if err := UploadFlagSet.Parse(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitCodeSuccess
+		}
+		return ExitCodeBadInput
+	}
 
 	config, err := loadConfig(flagConfigPath)
 	if err != nil {
-		ErrorLog.Fatalln(err)
+		Fatal(ExitCodeBadInput, err)
+	}
+
+	if err := reporter.OutputFormat(flagOutputFormat).Validate(); err != nil {
+		Fatalf(ExitCodeBadInput, "--output-format: %s", err)
 	}
 
 	// Get optional metadata provided by the user
@@ -218,10 +309,14 @@ func UploadCmd() {
 	}
 	reporter.LogMetadataEntries(InfoLog, metadata)
 
-	// Ensure only JUnit test reports will be processed and not other artifacts (logs, etc)
-	junitTestReportPaths, err := getJUnitTestReportPaths(flagJUnitInputPaths)
+	if flagInputFormat != "" {
+		config.Spec.Reporting.InputFormat = reporter.InputFormat(flagInputFormat)
+	}
+
+	// Ensure only test reports will be processed and not other artifacts (logs, etc)
+	junitTestReportPaths, err := getJUnitTestReportPaths(flagJUnitInputPaths, config.Spec.Reporting.InputFormat)
 	if err != nil {
-		ErrorLog.Fatalln(err)
+		Fatal(ExitCodeBadInput, err)
 	}
 
 	if flagJiraDestIssueID != "" {
@@ -236,25 +331,116 @@ func UploadCmd() {
 		config.Spec.Jira.Server.URL = flagJiraServerURL
 	}
 
-	InfoLog.Printf("Processing %d JUnit test reports %v", len(junitTestReportPaths), junitTestReportPaths)
+	if flagParallelUploads > 0 {
+		config.Spec.Jira.Server.MaxConcurrency = flagParallelUploads
+	}
+
+	// Interrupting the program cancels requests sent to Jira instead of leaving them unfinished
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var client reporter.JiraClient
+	if !flagJiraSyncDisabled {
+		// The token given with the -t/--jira-token flag is one of the credential sources, and it
+		// takes precedence over the sources configured in 'spec.jira.server.auth.token'
+		if token := viper.GetString("jira-token"); token != "" {
+			config.Spec.Jira.Server.Auth.Token.Value = token
+		}
+
+		if email := viper.GetString("jira-email"); email != "" {
+			config.Spec.Jira.Server.Email = email
+		}
+
+		client, err = reporter.NewJiraClient(config.Spec.Jira.Server)
+		if err != nil {
+			Fatalf(ExitCodeBadInput, "%s. Use the -t/--jira-token flag, set the '%s' env var or check 'spec.jira.server' in the config file", err, EnvNameJiraAccessToken)
+		}
+	}
+
+	var discoveredRoutes []reporter.ReportingRouteConfig
+	if flagJiraDestIssueID == "" && config.Spec.Jira.Discovery.JQL != "" {
+		if flagJiraSyncDisabled {
+			InfoLog.Println("[-n/--no-sync flag set] Destinations will not be discovered in Jira")
+		} else {
+			discoveredRoutes, err = reporter.DiscoverRoutes(ctx, client, config.Spec.Jira.Discovery)
+			if err != nil {
+				Fatal(ExitCodeUploadFailed, err)
+			}
+			config.Spec.Reporting.Routing = append(config.Spec.Reporting.Routing, discoveredRoutes...)
+		}
+	}
+
+	InfoLog.Printf("Processing %d test reports (format: %s) %v", len(junitTestReportPaths), config.Spec.Reporting.InputFormat, junitTestReportPaths)
 	reports, err := reporter.ProcessJUnitReports(junitTestReportPaths, config.Spec.Reporting)
 	if err != nil {
-		ErrorLog.Fatalln(err)
+		Fatal(ExitCodeBadInput, err)
 	}
 
+	// Not every discovered Story is covered by a single test run
+	reports = reporter.DropEmptyReports(reports, discoveredRoutes)
+
 	reporter.LogAggregateReports(InfoLog, reports)
 
+	exitCode := ExitCodeSuccess
+	violations := reporter.EvaluateQualityGates(reports, config.Spec.Gates)
+	if len(violations) > 0 {
+		for _, v := range violations {
+			WarnLog.Printf("Quality gate not met: %s", v)
+		}
+		exitCode = ExitCodeGateFailed
+	}
+
+	results := make([]reporter.UploadResult, len(reports))
+	for i, report := range reports {
+		results[i] = reporter.NewUploadResult(report)
+	}
+
 	if flagJiraSyncDisabled {
 		InfoLog.Println("[-n/--no-sync flag set] Synchronization with Jira has been disabled. No test reports will be uploaded")
-	} else {
-		token := viper.GetString("jira-token")
+		writeUploadResults(results, violations)
+		return exitCode
+	}
 
-		if token == "" {
-			ErrorLog.Fatalf("Jira access token not set. Use the -t/--jira-token flag or set the '%s' env var", EnvNameJiraAccessToken)
+	// Only the test reports with Test Suites routed to a report are attached to its Sub-task
+	for i, report := range reports {
+		desiredState, err := reporter.MergeDesiredState(config.Spec.Jira.DesiredState, report.DesiredState)
+		if err != nil {
+			Fatal(ExitCodeBadInput, err)
 		}
 
-		if err := reporter.UploadAggregateReports(reports, metadata, config, token); err != nil {
-			ErrorLog.Fatalln(err)
+		if !desiredState.Attachments.Enabled || len(report.Sources()) == 0 {
+			continue
 		}
+
+		reports[i].Attachments, err = reporter.LoadTestReportAttachments(report.Sources(), desiredState.Attachments.BundleName)
+		if err != nil {
+			Fatal(ExitCodeBadInput, err)
+		}
+	}
+
+	results, err = reporter.UploadAggregateReports(ctx, client, reports, metadata, config)
+	writeUploadResults(results, violations)
+	if err != nil {
+		Fatal(ExitCodeUploadFailed, err)
 	}
+
+	return exitCode
+}
+
+// writeUploadResults writes the results of the upload to the file set with the -o/--output-file flag, if any.
+func writeUploadResults(results []reporter.UploadResult, violations []reporter.QualityGateViolation) {
+	if flagOutputFile == "" {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := reporter.WriteUploadResults(&buf, reporter.OutputFormat(flagOutputFormat), results, violations); err != nil {
+		Fatalf(ExitCodeError, "Results could not be written: %s", err)
+	}
+
+	if err := os.WriteFile(flagOutputFile, buf.Bytes(), 0o644); err != nil {
+		Fatalf(ExitCodeError, "Results could not be written: %s", err)
+	}
+
+	InfoLog.Printf("Results written to '%s'", flagOutputFile)
 }
@@ -0,0 +1,189 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	jiraMyselfEndpoint      = "/rest/api/2/myself"
+	jiraCloudMyselfEndpoint = "/rest/api/3/myself"
+
+	// jiraCommentsPageSize is the number of comments fetched with a single request.
+	jiraCommentsPageSize = 100
+)
+
+// JiraUser represents a Jira account. The ID is the account ID on Jira Cloud, and the user key on Jira Data Center.
+type JiraUser struct {
+	ID   string
+	Name string
+}
+
+// JiraComment represents a comment of an Issue as returned by the Jira REST API.
+type JiraComment struct {
+	ID       string
+	AuthorID string
+	Body     string
+}
+
+type apiUser struct {
+	AccountID   string `json:"accountId"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// JiraUser converts a user returned by the Jira REST API to the internal representation.
+func (u apiUser) JiraUser() JiraUser {
+	user := JiraUser{ID: u.AccountID, Name: u.DisplayName}
+	if user.ID == "" {
+		user.ID = u.Key
+	}
+	if user.ID == "" {
+		user.ID = u.Name
+	}
+
+	return user
+}
+
+type apiResponseComment struct {
+	ID     string              `json:"id"`
+	Author apiUser             `json:"author"`
+	Body   apiIssueDescription `json:"body"`
+}
+
+type apiResponseComments struct {
+	StartAt  int                  `json:"startAt"`
+	Total    int                  `json:"total"`
+	Comments []apiResponseComment `json:"comments"`
+}
+
+// GetCurrentUser sends a request to Jira REST API to fetch the account the client is authenticated as.
+func (c JiraClient) GetCurrentUser(ctx context.Context) (user JiraUser, err error) {
+	endpoint := jiraMyselfEndpoint
+	if c.Flavor == JiraFlavorCloud {
+		endpoint = jiraCloudMyselfEndpoint
+	}
+
+	endpointURL, err := url.JoinPath(c.ServerURL, endpoint)
+	if err != nil {
+		return user, err
+	}
+
+	resp, err := c.sendRequest(ctx, "GET", endpointURL, nil)
+	if err != nil {
+		return user, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("current user could not be fetched. HTTP status code: %d", resp.StatusCode)
+	}
+
+	var data apiUser
+	if err := json.Unmarshal(resp.Body, &data); err != nil {
+		return user, err
+	}
+
+	return data.JiraUser(), nil
+}
+
+// GetComments sends requests to Jira REST API to fetch all comments of an Issue, ordered from the oldest to the newest.
+func (c JiraClient) GetComments(ctx context.Context, id string) (comments []JiraComment, err error) {
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id, "comment")
+	if err != nil {
+		return nil, err
+	}
+
+	for startAt := 0; ; {
+		query := url.Values{
+			"startAt":    {strconv.Itoa(startAt)},
+			"maxResults": {strconv.Itoa(jiraCommentsPageSize)},
+			"orderBy":    {"created"},
+		}
+
+		resp, err := c.sendRequest(ctx, "GET", endpointURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("comments of issue %s could not be fetched. HTTP status code: %d", id, resp.StatusCode)
+		}
+
+		var page apiResponseComments
+		if err := json.Unmarshal(resp.Body, &page); err != nil {
+			return nil, err
+		}
+
+		for _, comment := range page.Comments {
+			comments = append(comments, JiraComment{
+				ID:       comment.ID,
+				AuthorID: comment.Author.JiraUser().ID,
+				Body:     string(comment.Body),
+			})
+		}
+
+		startAt += len(page.Comments)
+		if len(page.Comments) == 0 || startAt >= page.Total {
+			return comments, nil
+		}
+	}
+}
+
+// AddComment sends a request to Jira REST API to add a comment to an Issue with the given ID.
+// On Jira Cloud, bodies using the wiki markup are converted to ADF.
+func (c JiraClient) AddComment(ctx context.Context, id string, body string) error {
+	infoLog(ctx).Printf("Adding a comment to Jira issue '%s'", id)
+
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id, "comment")
+	if err != nil {
+		return err
+	}
+
+	bodyValue, err := c.descriptionValue(body)
+	if err != nil {
+		return fmt.Errorf("comment could not be added to issue %s. Reason: %w", id, err)
+	}
+
+	payload, err := json.Marshal(map[string]any{"body": bodyValue})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.sendRequest(ctx, "POST", endpointURL, payload)
+	if err != nil {
+		return fmt.Errorf("comment could not be added to issue %s. Reason: %w", id, err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("comment could not be added to issue %s. HTTP status code: %d", id, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// DeleteComment sends a request to Jira REST API to delete a comment of an Issue with the given ID.
+func (c JiraClient) DeleteComment(ctx context.Context, id string, commentID string) error {
+	infoLog(ctx).Printf("Deleting comment '%s' of Jira issue '%s'", commentID, id)
+
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id, "comment", commentID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.sendRequest(ctx, "DELETE", endpointURL, nil)
+	if err != nil {
+		return fmt.Errorf("comment %s of issue %s could not be deleted. Reason: %w", commentID, id, err)
+	}
+
+	// A comment deleted by a previous attempt of a retried request is already gone
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("comment %s of issue %s could not be deleted. HTTP status code: %d", commentID, id, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -1,13 +1,20 @@
 package reporter
 
+import (
+	"regexp"
+	"text/template"
+	"time"
+)
+
 type Config struct {
 	APIVersion string     `mapstructure:"apiVersion"`
 	Spec       ConfigSpec `mapstructure:"spec"`
 }
 
 type ConfigSpec struct {
-	Jira      JiraConfig      `mapstructure:"jira"`
-	Reporting ReportingConfig `mapstructure:"reporting"`
+	Jira      JiraConfig         `mapstructure:"jira"`
+	Reporting ReportingConfig    `mapstructure:"reporting"`
+	Gates     QualityGatesConfig `mapstructure:"gates"`
 }
 
 type JiraConfig struct {
@@ -17,16 +24,70 @@ type JiraConfig struct {
 }
 
 type JiraServerConfig struct {
+	URL            string                `mapstructure:"url"`
+	Flavor         JiraFlavor            `mapstructure:"flavor"`
+	Email          string                `mapstructure:"email"`
+	Auth           JiraServerAuthConfig  `mapstructure:"auth"`
+	TLS            JiraServerTLSConfig   `mapstructure:"tls"`
+	Proxy          JiraServerProxyConfig `mapstructure:"proxy"`
+	Timeout        time.Duration         `mapstructure:"timeout"`
+	Retry          JiraServerRetryConfig `mapstructure:"retry"`
+	MaxConcurrency int                   `mapstructure:"maxConcurrency"`
+}
+
+type JiraServerAuthConfig struct {
+	Scheme   JiraAuthScheme         `mapstructure:"scheme"`
+	Token    SecretConfig           `mapstructure:"token"`
+	Username SecretConfig           `mapstructure:"username"`
+	Password SecretConfig           `mapstructure:"password"`
+	OAuth1   JiraServerOAuth1Config `mapstructure:"oauth1"`
+}
+
+type JiraServerOAuth1Config struct {
+	ConsumerKey SecretConfig `mapstructure:"consumerKey"`
+	AccessToken SecretConfig `mapstructure:"accessToken"`
+	PrivateKey  SecretConfig `mapstructure:"privateKey"`
+}
+
+// SecretConfig describes where the value of a secret is read from.
+type SecretConfig struct {
+	Value string `mapstructure:"value"`
+	Env   string `mapstructure:"env"`
+	File  string `mapstructure:"file"`
+}
+
+type JiraServerTLSConfig struct {
+	CAFile             string `mapstructure:"caFile"`
+	CertFile           string `mapstructure:"certFile"`
+	KeyFile            string `mapstructure:"keyFile"`
+	MinVersion         string `mapstructure:"minVersion"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+}
+
+type JiraServerProxyConfig struct {
 	URL string `mapstructure:"url"`
 }
 
+type JiraServerRetryConfig struct {
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+}
+
 // Jira issue auto-discovery configuration
 
 type JiraIssueDiscoveryConfig struct {
+	JQL     string                          `mapstructure:"jql"`
+	Mapping JiraIssueDiscoveryMappingConfig `mapstructure:"mapping"`
 	Summary JiraIssueDiscoverySummaryConfig `mapstructure:"summary"`
 	Labels  JiraIssueDiscoveryLabelsConfig  `mapstructure:"labels"`
 }
 
+type JiraIssueDiscoveryMappingConfig struct {
+	Property    string `mapstructure:"property"`
+	LabelPrefix string `mapstructure:"labelPrefix"`
+}
+
 type JiraIssueDiscoverySummaryConfig struct {
 	RequiredPrefix string `mapstructure:"requiredPrefix"`
 }
@@ -42,39 +103,118 @@ type JiraIssueDesiredStateConfig struct {
 	Description JiraIssueDesiredStateDescriptionConfig `mapstructure:"description"`
 	OnSuccess   JiraIssueDesiredStateConditionalConfig `mapstructure:"onSuccess"`
 	OnFailure   JiraIssueDesiredStateConditionalConfig `mapstructure:"onFailure"`
+	Comments    JiraIssueDesiredStateCommentsConfig    `mapstructure:"comments"`
+	Attachments JiraIssueDesiredStateAttachmentsConfig `mapstructure:"attachments"`
+	Bugs        JiraIssueDesiredStateBugsConfig        `mapstructure:"bugs"`
 }
 
 type JiraIssueDesiredStateSummaryConfig struct {
 	Contents          string `mapstructure:"contents"`
 	IncludeTestCounts bool   `mapstructure:"includeTestCounts"`
+	Template          string `mapstructure:"template"`
 }
 
 type JiraIssueDesiredStateDescriptionConfig struct {
 	TemplatePath string `mapstructure:"templatePath"`
 }
 
+type JiraIssueDesiredStateCommentsConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	TemplatePath string `mapstructure:"templatePath"`
+	KeepLast     int    `mapstructure:"keepLast"`
+}
+
+type JiraIssueDesiredStateAttachmentsConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	BundleName      string `mapstructure:"bundleName"`
+	ReplacePrevious bool   `mapstructure:"replacePrevious"`
+}
+
+type JiraIssueDesiredStateBugsConfig struct {
+	Enabled         bool            `mapstructure:"enabled"`
+	Project         string          `mapstructure:"project"`
+	IssueType       string          `mapstructure:"issueType"`
+	SummaryPrefix   string          `mapstructure:"summaryPrefix"`
+	TemplatePath    string          `mapstructure:"templatePath"`
+	Labels          []string        `mapstructure:"labels"`
+	LinkType        string          `mapstructure:"linkType"`
+	MaxPerReport    int             `mapstructure:"maxPerReport"`
+	OnPass          BugOnPassAction `mapstructure:"onPass"`
+	CloseTransition string          `mapstructure:"closeTransition"`
+}
+
 type JiraIssueDesiredStateConditionalConfig struct {
-	Labels []string `mapstructure:"labels"`
+	Labels     []string `mapstructure:"labels"`
+	Transition string   `mapstructure:"transition"`
 }
 
 // Reporting configuration
 
 type ReportingConfig struct {
-	Routing []ReportingRouteConfig `mapstructure:"routing"`
+	InputFormat    InputFormat                  `mapstructure:"inputFormat"`
+	Routing        []ReportingRouteConfig       `mapstructure:"routing"`
+	Deduplication  ReportingDeduplicationConfig `mapstructure:"deduplication"`
+	MaxConcurrency int                          `mapstructure:"maxConcurrency"`
+}
+
+type ReportingDeduplicationConfig struct {
+	Policy DeduplicationPolicy `mapstructure:"policy"`
 }
 
 type ReportingRouteConfig struct {
 	Destination string                     `mapstructure:"destination"`
 	TestSuites  []ReportingTestSuiteConfig `mapstructure:"testSuites"`
+	Exclude     []ReportingTestSuiteConfig `mapstructure:"exclude"`
+	// Desired state merged over the global one for the reports of this route
+	DesiredState JiraIssueDesiredStateOverrideConfig `mapstructure:"desiredState"`
+
+	// Template compiled by ReportingConfig.Compile if the destination is dynamic
+	destinationTemplate *template.Template
+	// Set for routes returned by DiscoverRoutes, whose reports only include Test Suites with selected Test Cases
+	discovered bool
 }
 
 type ReportingTestSuiteConfig struct {
-	Name      string                    `mapstructure:"name"`
-	Property  string                    `mapstructure:"property"`
-	TestCases []ReportingTestCaseConfig `mapstructure:"testCases"`
+	ReportingRuleConfig `mapstructure:",squash"`
+	TestCases           []ReportingTestCaseConfig `mapstructure:"testCases"`
+	Exclude             []ReportingTestCaseConfig `mapstructure:"exclude"`
+
+	// Exclusion rules inherited from the route this rule was defined in
+	routeExclude []ReportingTestSuiteConfig
 }
 
 type ReportingTestCaseConfig struct {
-	Name     string `mapstructure:"name"`
-	Property string `mapstructure:"property"`
+	ReportingRuleConfig `mapstructure:",squash"`
+}
+
+// ReportingRuleConfig holds the criteria shared by Test Suite and Test Case rules.
+// An entity is matched by the rule if it satisfies any of the criteria that have been set.
+type ReportingRuleConfig struct {
+	Name          string `mapstructure:"name"`
+	NameRegex     string `mapstructure:"nameRegex"`
+	NameGlob      string `mapstructure:"nameGlob"`
+	Property      string `mapstructure:"property"`
+	PropertyRegex string `mapstructure:"propertyRegex"`
+
+	// Patterns compiled by ReportingConfig.Compile
+	compiled           bool
+	nameRegex          *regexp.Regexp
+	nameGlob           *regexp.Regexp
+	property           propertyExpression
+	propertyRegexName  string
+	propertyRegexValue *regexp.Regexp
+}
+
+// Quality gates configuration
+
+type QualityGatesConfig struct {
+	Overall   QualityGateConfig `mapstructure:"overall"`
+	PerReport QualityGateConfig `mapstructure:"perReport"`
+}
+
+type QualityGateConfig struct {
+	MinPassRate    float64  `mapstructure:"minPassRate"`
+	MaxFailed      *int     `mapstructure:"maxFailed"`
+	NoErrored      bool     `mapstructure:"noErrored"`
+	RequiredSuites []string `mapstructure:"requiredSuites"`
 }
@@ -0,0 +1,125 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/joshdk/go-junit"
+)
+
+// DeduplicationPolicy defines which result is kept when a Test Case is found multiple times
+// in the test reports, for example because it has been rerun after a failure.
+type DeduplicationPolicy string
+
+const (
+	// DeduplicationNone keeps all results. Every occurrence of a Test Case is counted.
+	DeduplicationNone DeduplicationPolicy = "none"
+	// DeduplicationLastWins keeps the result of the last occurrence of a Test Case.
+	DeduplicationLastWins DeduplicationPolicy = "last-wins"
+	// DeduplicationAnyPassWins keeps a passed result if the Test Case passed at least once,
+	// and the result of the last occurrence otherwise.
+	DeduplicationAnyPassWins DeduplicationPolicy = "any-pass-wins"
+	// DeduplicationWorstWins keeps the most severe result of a Test Case,
+	// where errored results are more severe than failed, skipped and passed results (in this order).
+	DeduplicationWorstWins DeduplicationPolicy = "worst-wins"
+)
+
+// Validate checks whether the policy is one of the supported deduplication policies.
+func (p DeduplicationPolicy) Validate() error {
+	switch p {
+	case "", DeduplicationNone, DeduplicationLastWins, DeduplicationAnyPassWins, DeduplicationWorstWins:
+		return nil
+	}
+
+	return fmt.Errorf("unknown deduplication policy '%s'. Expected one of: %s, %s, %s, %s",
+		p, DeduplicationNone, DeduplicationLastWins, DeduplicationAnyPassWins, DeduplicationWorstWins)
+}
+
+var statusSeverity = map[junit.Status]int{
+	junit.StatusPassed:  0,
+	junit.StatusSkipped: 1,
+	junit.StatusFailed:  2,
+	junit.StatusError:   3,
+}
+
+// prefers checks whether the policy prefers the candidate result over the currently kept one.
+// Test Cases are visited in the order they were loaded, so later results win all ties.
+func (p DeduplicationPolicy) prefers(candidate TestCase, kept TestCase) bool {
+	switch p {
+	case DeduplicationAnyPassWins:
+		return candidate.Status == junit.StatusPassed || kept.Status != junit.StatusPassed
+	case DeduplicationWorstWins:
+		return statusSeverity[candidate.Status] >= statusSeverity[kept.Status]
+	}
+
+	return true
+}
+
+type testCaseKey struct {
+	TestSuite string
+	Classname string
+	Name      string
+}
+
+// Deduplicate merges all occurrences of the same Test Case (identified by the Test Suite name, classname
+// and name) into a single result chosen by a given policy, and marks Test Cases that both failed and passed
+// as flaky. The result is kept at the position of the first occurrence, and Test Suites left with no
+// Test Cases are removed. Test counts are recalculated afterwards.
+func (r *AggregateReport) Deduplicate(policy DeduplicationPolicy) {
+	if policy == "" || policy == DeduplicationNone {
+		return
+	}
+
+	type position struct{ suite, test int }
+
+	positions := map[testCaseKey]position{}
+	hasPassed := map[testCaseKey]bool{}
+	hasFailed := map[testCaseKey]bool{}
+
+	suites := make([]TestSuite, len(r.TestSuites))
+	for i, suite := range r.TestSuites {
+		suites[i] = TestSuite{Name: suite.Name}
+
+		for _, test := range suite.TestCases {
+			key := testCaseKey{TestSuite: suite.Name, Classname: test.Classname, Name: test.Name}
+			hasPassed[key] = hasPassed[key] || test.Status == junit.StatusPassed
+			hasFailed[key] = hasFailed[key] || test.IsFailed()
+
+			pos, ok := positions[key]
+			if !ok {
+				test.Attempts = 1
+				positions[key] = position{i, len(suites[i].TestCases)}
+				suites[i].TestCases = append(suites[i].TestCases, test)
+				continue
+			}
+
+			kept := &suites[pos.suite].TestCases[pos.test]
+			attempts := kept.Attempts + 1
+			if policy.prefers(test, *kept) {
+				*kept = test
+			}
+			kept.Attempts = attempts
+		}
+	}
+
+	deduplicated := []TestSuite{}
+	for i, suite := range suites {
+		// Remove Test Suites left empty only because all of their Test Cases were duplicates
+		if len(suite.TestCases) == 0 && len(r.TestSuites[i].TestCases) > 0 {
+			continue
+		}
+		deduplicated = append(deduplicated, suite)
+	}
+	r.TestSuites = deduplicated
+
+	for i := range r.TestSuites {
+		suite := &r.TestSuites[i]
+		for j := range suite.TestCases {
+			test := &suite.TestCases[j]
+			key := testCaseKey{TestSuite: suite.Name, Classname: test.Classname, Name: test.Name}
+			test.Flaky = hasPassed[key] && hasFailed[key]
+			suite.Counts.AddTestCase(*test)
+		}
+	}
+
+	r.AggregateCounts()
+}
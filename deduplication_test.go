@@ -0,0 +1,53 @@
+package reporter
+
+import (
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	// The same Test Case failed, errored and then passed
+	paths := []string{
+		"testdata/valid/simple_failure.xml",
+		"testdata/valid/simple_error.xml",
+		"testdata/valid/simple.xml",
+		"testdata/valid/multiple_test_cases.xml",
+	}
+
+	expectedCounts := map[DeduplicationPolicy]Counts{
+		DeduplicationNone:        {Passed: 6, Failed: 1, Errored: 1, Total: 8},
+		DeduplicationLastWins:    {Passed: 6, Flaky: 1, Total: 6},
+		DeduplicationAnyPassWins: {Passed: 6, Flaky: 1, Total: 6},
+		DeduplicationWorstWins:   {Passed: 5, Errored: 1, Flaky: 1, Total: 6},
+	}
+
+	for policy, expected := range expectedCounts {
+		config := ReportingConfig{Deduplication: ReportingDeduplicationConfig{Policy: policy}}
+		reports, err := ProcessJUnitReports(paths, config)
+		if err != nil {
+			t.Fatalf("%s: ProcessJUnitReports failed: %s", policy, err)
+		}
+
+		report := reports[0]
+		if report.Counts != expected {
+			t.Fatalf("%s: expected counts %+v, got %+v", policy, expected, report.Counts)
+		}
+
+		if policy == DeduplicationNone {
+			continue
+		}
+
+		if len(report.TestSuites) != 2 {
+			t.Fatalf("%s: expected Test Suites with duplicates only to be removed, got %d Test Suites", policy, len(report.TestSuites))
+		}
+
+		simple := report.TestSuites[0].TestCases[0]
+		if simple.Attempts != 3 || !simple.Flaky {
+			t.Fatalf("%s: expected 3 attempts of a flaky Test Case, got %+v", policy, simple)
+		}
+	}
+
+	config := ReportingConfig{Deduplication: ReportingDeduplicationConfig{Policy: "first-wins"}}
+	if err := config.Compile(); err == nil {
+		t.Fatalf("Compile should have failed for an unknown deduplication policy")
+	}
+}
@@ -0,0 +1,48 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// JiraIssueDesiredStateOverrideConfig holds the options of the desired state set for a single route, structured
+// like JiraIssueDesiredStateConfig. Only the options present in it replace the global ones, so options can be
+// set to false, 0 or an empty list as well.
+type JiraIssueDesiredStateOverrideConfig map[string]any
+
+// MergeDesiredState returns the desired state with the options present in an override applied over it.
+// Lists present in the override replace the inherited ones as a whole.
+func MergeDesiredState(base JiraIssueDesiredStateConfig, override JiraIssueDesiredStateOverrideConfig) (JiraIssueDesiredStateConfig, error) {
+	if override == nil {
+		return base, nil
+	}
+
+	// Lists of the base are shared with the global config, so they are replaced rather than written to
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &base,
+		ZeroFields:       true,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return base, err
+	}
+
+	if err := decoder.Decode(map[string]any(override)); err != nil {
+		return base, err
+	}
+
+	return base, nil
+}
+
+// withDesiredStateOverride returns a copy of the config, in which the desired state of a single report
+// is merged over the global desired state.
+func (c Config) withDesiredStateOverride(override JiraIssueDesiredStateOverrideConfig) (Config, error) {
+	desiredState, err := MergeDesiredState(c.Spec.Jira.DesiredState, override)
+	if err != nil {
+		return c, fmt.Errorf("desired state of the route could not be merged: %w", err)
+	}
+	c.Spec.Jira.DesiredState = desiredState
+
+	return c, nil
+}
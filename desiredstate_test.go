@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeDesiredState(t *testing.T) {
+	base := createUploaderTestConfig("").Spec.Jira.DesiredState
+	base.Comments.KeepLast = 5
+
+	if merged, err := MergeDesiredState(base, nil); err != nil || merged.Summary != base.Summary || !slices.Equal(merged.OnFailure.Labels, base.OnFailure.Labels) {
+		t.Fatalf("expected the base to be returned without an override, got %+v (%v)", merged, err)
+	}
+
+	// Keys are lowercase when the override is loaded from a config file
+	override := JiraIssueDesiredStateOverrideConfig{
+		"summary":     map[string]any{"contents": "Team A test results", "includetestcounts": false},
+		"description": map[string]any{"templatePath": "team-a.tmpl"},
+		"onSuccess":   map[string]any{"labels": []any{}},
+		"onfailure":   map[string]any{"labels": []any{"TEAM-A-FAILED"}},
+		"comments":    map[string]any{"enabled": true, "keepLast": 0},
+	}
+
+	merged, err := MergeDesiredState(base, override)
+	if err != nil {
+		t.Fatalf("MergeDesiredState failed: %s", err)
+	}
+
+	if merged.Summary.Contents != "Team A test results" || merged.Summary.IncludeTestCounts || merged.Description.TemplatePath != "team-a.tmpl" {
+		t.Fatalf("expected the summary and description to be overridden, got %+v", merged)
+	}
+
+	if len(merged.OnSuccess.Labels) != 0 || !slices.Equal(merged.OnFailure.Labels, []string{"TEAM-A-FAILED"}) {
+		t.Fatalf("expected the labels to be replaced, got %v and %v", merged.OnSuccess.Labels, merged.OnFailure.Labels)
+	}
+
+	if !merged.Comments.Enabled || merged.Comments.KeepLast != 0 || merged.Attachments.Enabled {
+		t.Fatalf("expected only the options present in the override to be changed, got %+v", merged)
+	}
+
+	if base.Summary.Contents != "Automated test suite execution status" || !slices.Equal(base.OnSuccess.Labels, []string{"PASSED"}) {
+		t.Fatalf("expected the base to be left untouched, got %+v", base)
+	}
+
+	if _, err := MergeDesiredState(base, JiraIssueDesiredStateOverrideConfig{"summary": "Team A"}); err == nil {
+		t.Fatalf("MergeDesiredState should have failed for an invalid override")
+	}
+}
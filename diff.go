@@ -0,0 +1,126 @@
+package reporter
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// issueFieldDiff describes whether a field of an Issue differs from its desired state.
+type issueFieldDiff struct {
+	name    string
+	changed bool
+}
+
+// diffIssueFields compares the current state of an Issue with its desired state, and returns the names of the fields
+// that would be changed by an update and the names of the fields that are already up to date.
+func diffIssueFields(issue JiraIssue, fields IssueDesiredStateFields) (changed []string, unchanged []string) {
+	diffs := []issueFieldDiff{
+		{"summary", issue.Summary != fields.Summary},
+		{"description", !isSameDescription(issue.Description, fields.Description)},
+		{"labels", !isSameLabels(issue.Labels, fields.Labels)},
+	}
+
+	for _, diff := range diffs {
+		if diff.changed {
+			changed = append(changed, diff.name)
+		} else {
+			unchanged = append(unchanged, diff.name)
+		}
+	}
+
+	return changed, unchanged
+}
+
+// isSameDescription checks whether the current description of an Issue matches the desired one. Whitespace
+// at the end of lines is ignored, as Jira may strip it. Descriptions returned by Jira Cloud as ADF documents
+// are compared with the desired description converted to ADF, ignoring the attributes Jira adds to stored
+// documents on its own (see jiraADFAttributes). Other attributes, such as link targets, are compared.
+func isSameDescription(current string, desired string) bool {
+	if normalizeDescription(current) == normalizeDescription(desired) {
+		return true
+	}
+
+	if !isADFDocument(current) {
+		return false
+	}
+
+	rendered := []byte(desired)
+	if !isADFDocument(desired) {
+		var err error
+		if rendered, err = renderADF(desired); err != nil {
+			return false
+		}
+	}
+
+	var currentDoc, desiredDoc any
+	if json.Unmarshal([]byte(current), &currentDoc) != nil || json.Unmarshal(rendered, &desiredDoc) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(stripADFAttributes(currentDoc), stripADFAttributes(desiredDoc))
+}
+
+// jiraADFAttributes lists the attributes Jira Cloud adds to the nodes of stored ADF documents, or changes to
+// its own defaults. They do not change the content of a document.
+var jiraADFAttributes = []string{"localId", "layout", "width", "isNumberColumnEnabled"}
+
+// stripADFAttributes removes the attributes listed in jiraADFAttributes from all nodes and marks of a decoded
+// ADF document. Attributes left empty are removed as well.
+func stripADFAttributes(node any) any {
+	switch n := node.(type) {
+	case map[string]any:
+		stripped := make(map[string]any, len(n))
+		for key, value := range n {
+			if key == "localId" {
+				continue
+			}
+
+			if attrs, ok := value.(map[string]any); ok && key == "attrs" {
+				kept := map[string]any{}
+				for name, attr := range attrs {
+					if !slices.Contains(jiraADFAttributes, name) {
+						kept[name] = attr
+					}
+				}
+				if len(kept) == 0 {
+					continue
+				}
+				value = kept
+			}
+
+			stripped[key] = stripADFAttributes(value)
+		}
+		return stripped
+
+	case []any:
+		stripped := make([]any, len(n))
+		for i, value := range n {
+			stripped[i] = stripADFAttributes(value)
+		}
+		return stripped
+	}
+
+	return node
+}
+
+// normalizeDescription unifies line endings and removes trailing whitespace from each line of a description.
+func normalizeDescription(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, func(r rune) bool { return r == ' ' || r == '\t' })
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isSameLabels checks whether two sets of labels are equal, regardless of their order.
+func isSameLabels(current []string, desired []string) bool {
+	a := slices.Clone(current)
+	b := slices.Clone(desired)
+	slices.Sort(a)
+	slices.Sort(b)
+
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
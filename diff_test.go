@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiffIssueFields(t *testing.T) {
+	issue := JiraIssue{
+		Summary:     "Automated test suite execution status (1/2 PASSED)",
+		Description: "h2. Results  \r\n\r\n|| Suite || Passed ||\r\n| Networking | 1 |\r\n",
+		Labels:      []string{"FAILED", "ci"},
+	}
+
+	testCases := []struct {
+		fields  IssueDesiredStateFields
+		changed []string
+	}{
+		{IssueDesiredStateFields{Summary: issue.Summary, Description: "h2. Results\n\n|| Suite || Passed ||\n| Networking | 1 |", Labels: []string{"ci", "FAILED"}}, nil},
+		{IssueDesiredStateFields{Summary: "Automated test suite execution status (2/2 PASSED)", Description: issue.Description, Labels: []string{"PASSED", "ci"}}, []string{"summary", "labels"}},
+		{IssueDesiredStateFields{Summary: issue.Summary, Description: "h2. Results\n\n|| Suite || Passed ||\n| Networking | 2 |", Labels: issue.Labels}, []string{"description"}},
+	}
+
+	for i, tc := range testCases {
+		changed, unchanged := diffIssueFields(issue, tc.fields)
+		if !slices.Equal(changed, tc.changed) || len(changed)+len(unchanged) != 3 {
+			t.Fatalf("%d: expected changed fields %v, got %v (unchanged: %v)", i, tc.changed, changed, unchanged)
+		}
+	}
+}
+
+func TestIsSameDescriptionWithADF(t *testing.T) {
+	markup := "h2. Results\n\n* one\n* two"
+	doc, err := renderADF(markup)
+	if err != nil {
+		t.Fatalf("renderADF failed: %s", err)
+	}
+
+	if !isSameDescription(string(doc), markup) {
+		t.Fatalf("expected the ADF document to match the wiki markup it was rendered from")
+	}
+
+	if isSameDescription(string(doc), "h2. Results\n\n* one") {
+		t.Fatalf("expected the ADF document not to match a different description")
+	}
+
+	// Jira Cloud adds attributes to the nodes of stored documents
+	stored := `{"type":"doc","version":1,"content":[` +
+		`{"type":"heading","attrs":{"level":2,"localId":"a1b2"},"content":[{"type":"text","text":"Results"}]},` +
+		`{"type":"bulletList","attrs":{"localId":"c3d4"},"content":[` +
+		`{"type":"listItem","attrs":{"localId":"e5f6"},"content":[{"type":"paragraph","attrs":{"localId":"g7h8"},"content":[{"type":"text","text":"one"}]}]},` +
+		`{"type":"listItem","attrs":{"localId":"i9j0"},"content":[{"type":"paragraph","attrs":{"localId":"k1l2"},"content":[{"type":"text","text":"two"}]}]}]}]}`
+	if !isSameDescription(stored, markup) {
+		t.Fatalf("expected the ADF document stored by Jira to match the wiki markup it was rendered from")
+	}
+
+	// Attributes that change the content are compared
+	for _, desired := range []string{
+		strings.Replace(stored, `"level":2`, `"level":3`, 1),
+		strings.Replace(stored, `{"type":"text","text":"one"}`, `{"type":"text","text":"one","marks":[{"type":"link","attrs":{"href":"https://example.com/a"}}]}`, 1),
+	} {
+		if isSameDescription(stored, desired) {
+			t.Fatalf("expected the ADF document not to match a document with different attributes: %s", desired)
+		}
+	}
+
+	linked := func(href string) string {
+		return `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[` +
+			`{"type":"text","text":"logs","marks":[{"type":"link","attrs":{"href":"` + href + `"}}]}]}]}`
+	}
+	if isSameDescription(linked("https://example.com/run/1"), linked("https://example.com/run/2")) {
+		t.Fatalf("expected documents with different link targets not to match")
+	}
+}
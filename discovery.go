@@ -0,0 +1,100 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DiscoverRoutes searches Jira for the destination Stories matching the JQL query given in the discovery config,
+// and returns a route for each of them. Test Cases are mapped to the discovered Stories in two ways:
+//
+//   - Test Suites and Test Cases with a property (mapping.property) set to the key or to one of the labels of a Story
+//   - Test Suites named after a label of a Story, once the label prefix (mapping.labelPrefix) has been trimmed
+//
+// Routes are returned in the order of the search results, so that they can be appended to the routes of the config.
+func DiscoverRoutes(ctx context.Context, client JiraSearchAPI, config JiraIssueDiscoveryConfig) ([]ReportingRouteConfig, error) {
+	if config.JQL == "" {
+		return nil, nil
+	}
+
+	mapping := config.Mapping
+	if mapping.Property == "" && mapping.LabelPrefix == "" {
+		return nil, errors.New("discovery.mapping: either property or labelPrefix has to be set")
+	}
+
+	issues, err := client.SearchIssues(ctx, config.JQL)
+	if err != nil {
+		return nil, fmt.Errorf("destinations could not be discovered: %w", err)
+	}
+
+	var routes []ReportingRouteConfig
+	for _, issue := range issues {
+		route := ReportingRouteConfig{Destination: issue.ID, discovered: true}
+
+		if mapping.Property != "" {
+			rule := newPropertyMappingRule(mapping.Property, append([]string{issue.ID}, issue.Labels...))
+			route.TestSuites = append(route.TestSuites,
+				ReportingTestSuiteConfig{ReportingRuleConfig: rule},
+				ReportingTestSuiteConfig{
+					ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+					TestCases:           []ReportingTestCaseConfig{{rule}},
+				},
+			)
+		}
+
+		if mapping.LabelPrefix != "" {
+			for _, label := range issue.Labels {
+				if suiteName, ok := strings.CutPrefix(label, mapping.LabelPrefix); ok && suiteName != "" {
+					route.TestSuites = append(route.TestSuites, ReportingTestSuiteConfig{ReportingRuleConfig: ReportingRuleConfig{Name: suiteName}})
+				}
+			}
+		}
+
+		// A route without rules would match all Test Suites
+		if len(route.TestSuites) == 0 {
+			warnLog(ctx).Printf("Discovered Jira issue '%s' is not mapped to any test suite. Skipping", issue.ID)
+			continue
+		}
+
+		routes = append(routes, route)
+	}
+
+	infoLog(ctx).Printf("Destinations discovered in Jira: %d", len(routes))
+
+	return routes, nil
+}
+
+// newPropertyMappingRule returns a compiled rule matching entities with a given property set to any of the given values.
+func newPropertyMappingRule(name string, values []string) ReportingRuleConfig {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+
+	return ReportingRuleConfig{
+		Property: fmt.Sprintf("%s IN (%s)", name, strings.Join(quoted, ", ")),
+		property: propertyInExpression{name, values},
+		compiled: true,
+	}
+}
+
+// DropEmptyReports removes the reports of the given routes, for which no Test Cases have been selected.
+// Routes of discovered destinations are dropped this way, as not every discovered Story is covered by every test run.
+func DropEmptyReports(reports []AggregateReport, routes []ReportingRouteConfig) []AggregateReport {
+	var kept []AggregateReport
+	for _, report := range reports {
+		isRouted := slices.ContainsFunc(routes, func(r ReportingRouteConfig) bool { return r.Destination == report.Destination })
+		if isRouted && report.Counts.Total == 0 {
+			InfoLog.Printf("No test cases found for Jira issue '%s'. Skipping", report.Destination)
+			continue
+		}
+
+		kept = append(kept, report)
+	}
+
+	return kept
+}
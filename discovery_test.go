@@ -0,0 +1,84 @@
+package reporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+)
+
+func TestDiscoverRoutes(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", []string{"TELCO-V10N-ST", "suite:Networking"}, "")
+	jira.addIssue("EXAMPLE-20", "Story", "QE Story", []string{"TELCO-V10N-ST", "ptp"}, "")
+	jira.addIssue("EXAMPLE-25", "Story", "QE Story", []string{"TELCO-V10N-ST"}, "")
+	jira.addIssue("EXAMPLE-30", "Story", "QE Story", []string{"suite:Storage"}, "")
+	client := newTestJiraClient(t, server.URL)
+
+	config := JiraIssueDiscoveryConfig{
+		JQL:     `project = "EXAMPLE" AND labels = "TELCO-V10N-ST"`,
+		Mapping: JiraIssueDiscoveryMappingConfig{Property: "jira-story", LabelPrefix: "suite:"},
+	}
+
+	routes, err := DiscoverRoutes(context.Background(), client, config)
+	if err != nil {
+		t.Fatalf("DiscoverRoutes failed: %s", err)
+	}
+
+	if len(routes) != 3 || routes[0].Destination != "EXAMPLE-15" || routes[2].Destination != "EXAMPLE-25" {
+		t.Fatalf("expected a route for each discovered issue, got %+v", routes)
+	}
+
+	suites := []junit.Suite{
+		{
+			Name: "Networking",
+			Tests: []junit.Test{
+				{Name: "one", Status: junit.StatusPassed},
+				{Name: "two", Status: junit.StatusFailed, Properties: map[string]string{"jira-story": "ptp"}},
+			},
+		},
+		{
+			Name:       "PTP",
+			Properties: map[string]string{"jira-story": "EXAMPLE-20"},
+			Tests: []junit.Test{
+				{Name: "three", Status: junit.StatusPassed},
+			},
+		},
+		{
+			Name: "Storage",
+			Tests: []junit.Test{
+				{Name: "four", Status: junit.StatusPassed},
+			},
+		},
+	}
+
+	reports, err := ProcessJUnitRoutes(suites, routes)
+	if err != nil {
+		t.Fatalf("ProcessJUnitRoutes failed: %s", err)
+	}
+	reports = DropEmptyReports(reports, routes)
+
+	expected := map[string]Counts{
+		"EXAMPLE-15": {Passed: 1, Failed: 1, Total: 2},
+		"EXAMPLE-20": {Passed: 1, Failed: 1, Total: 2},
+	}
+	if len(reports) != len(expected) {
+		t.Fatalf("expected %d reports, got %d: %+v", len(expected), len(reports), reports)
+	}
+
+	for _, report := range reports {
+		if report.Counts != expected[report.Destination] {
+			t.Fatalf("expected counts %+v for %s, got %+v", expected[report.Destination], report.Destination, report.Counts)
+		}
+	}
+
+	// Test Suites are only added to the reports of the Stories their Test Cases have been mapped to
+	if suites := reports[1].TestSuites; len(suites) != 2 || suites[0].Name != "Networking" || len(suites[0].TestCases) != 1 {
+		t.Fatalf("unexpected test suites for %s: %+v", reports[1].Destination, suites)
+	}
+
+	config.Mapping = JiraIssueDiscoveryMappingConfig{}
+	if _, err := DiscoverRoutes(context.Background(), client, config); err == nil {
+		t.Fatalf("expected an error for a discovery config without mapping")
+	}
+}
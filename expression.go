@@ -0,0 +1,381 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// propertyExpression represents a compiled boolean expression evaluated against JUnit properties.
+//
+// The grammar of the expressions is as follows (keywords are case-insensitive):
+//
+//	expression := and { "OR" and }
+//	and        := unary { "AND" unary }
+//	unary      := "NOT" unary | "EXISTS" name | "(" expression ")" | comparison
+//	comparison := name ( "=" value | "!=" value | [ "NOT" ] "IN" "(" value { "," value } ")" )
+//
+// Values containing whitespace, parentheses or commas have to be enclosed in quotes. Rules in the legacy
+// form, a single name=value pair without any keywords, are accepted as well (see parseLegacyPropertyRule).
+type propertyExpression interface {
+	evaluate(properties map[string]string) bool
+}
+
+type propertyAndExpression struct {
+	operands []propertyExpression
+}
+
+func (e propertyAndExpression) evaluate(properties map[string]string) bool {
+	for _, operand := range e.operands {
+		if !operand.evaluate(properties) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type propertyOrExpression struct {
+	operands []propertyExpression
+}
+
+func (e propertyOrExpression) evaluate(properties map[string]string) bool {
+	for _, operand := range e.operands {
+		if operand.evaluate(properties) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type propertyNotExpression struct {
+	operand propertyExpression
+}
+
+func (e propertyNotExpression) evaluate(properties map[string]string) bool {
+	return !e.operand.evaluate(properties)
+}
+
+type propertyExistsExpression struct {
+	name string
+}
+
+func (e propertyExistsExpression) evaluate(properties map[string]string) bool {
+	_, ok := properties[e.name]
+	return ok
+}
+
+type propertyInExpression struct {
+	name   string
+	values []string
+}
+
+func (e propertyInExpression) evaluate(properties map[string]string) bool {
+	value, ok := properties[e.name]
+	return ok && slices.Contains(e.values, value)
+}
+
+// propertyLegacyExpression compares a property with a value the same way as the rules preceding the expressions:
+// missing properties are treated as empty, and rules without '=' never match.
+type propertyLegacyExpression struct {
+	name  string
+	value string
+	valid bool
+}
+
+func (e propertyLegacyExpression) evaluate(properties map[string]string) bool {
+	return e.valid && properties[e.name] == e.value
+}
+
+// parsePropertyExpression compiles a property rule into an expression that can be evaluated
+// against the properties of Test Suites and Test Cases.
+func parsePropertyExpression(input string) (propertyExpression, error) {
+	expr, err := parsePropertyExpressionGrammar(input)
+	if err != nil {
+		if legacy, ok := parseLegacyPropertyRule(input); ok {
+			return legacy, nil
+		}
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+func parsePropertyExpressionGrammar(input string) (propertyExpression, error) {
+	p := propertyExpressionParser{input: input}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipWhitespace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected '%s'", p.input[p.pos:])
+	}
+
+	return expr, nil
+}
+
+// parseLegacyPropertyRule accepts rules written before the expressions were introduced, which are not valid
+// expressions, e.g. because their value contains whitespace, parentheses or commas. Such rules are split on
+// the first '=' and the rest is the value. Inputs containing uppercase keywords or quoted values are not treated
+// as legacy rules, so that errors in expressions are still reported. Keywords in other cases are common words
+// of legacy values, e.g. "test-type=Functional and Performance".
+func parseLegacyPropertyRule(input string) (propertyExpression, bool) {
+	name, value, found := strings.Cut(input, "=")
+	if name == "" || strings.ContainsAny(name, "!()',\"") || strings.ContainsFunc(name, unicode.IsSpace) {
+		return nil, false
+	}
+
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return nil, false
+	}
+
+	for _, word := range strings.Fields(input) {
+		if slices.Contains(propertyExpressionKeywords, word) {
+			return nil, false
+		}
+	}
+
+	return propertyLegacyExpression{name: name, value: value, valid: found}, true
+}
+
+// propertyExpressionKeywords lists the keywords of the expressions, which are never part of legacy rules.
+var propertyExpressionKeywords = []string{"AND", "OR", "NOT", "IN", "EXISTS"}
+
+type propertyExpressionParser struct {
+	input string
+	pos   int
+}
+
+func (p *propertyExpressionParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, a...), p.pos+1)
+}
+
+func (p *propertyExpressionParser) skipWhitespace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume advances the parser if the remaining input starts with a given symbol.
+func (p *propertyExpressionParser) consume(symbol string) bool {
+	p.skipWhitespace()
+	if strings.HasPrefix(p.input[p.pos:], symbol) {
+		p.pos += len(symbol)
+		return true
+	}
+
+	return false
+}
+
+// consumeKeyword advances the parser if the remaining input starts with a given keyword
+// followed by a whitespace, an opening parenthesis or the end of the input.
+func (p *propertyExpressionParser) consumeKeyword(keyword string) bool {
+	p.skipWhitespace()
+
+	end := p.pos + len(keyword)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], keyword) {
+		return false
+	}
+
+	if end < len(p.input) && !unicode.IsSpace(rune(p.input[end])) && p.input[end] != '(' {
+		return false
+	}
+
+	p.pos = end
+	return true
+}
+
+// scan reads characters from the input until a whitespace or one of the given delimiters is found.
+func (p *propertyExpressionParser) scan(delimiters string) string {
+	p.skipWhitespace()
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if unicode.IsSpace(rune(c)) || strings.IndexByte(delimiters, c) >= 0 {
+			break
+		}
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+func (p *propertyExpressionParser) parseOr() (propertyExpression, error) {
+	var operands []propertyExpression
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+
+		if !p.consumeKeyword("OR") {
+			break
+		}
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+
+	return propertyOrExpression{operands}, nil
+}
+
+func (p *propertyExpressionParser) parseAnd() (propertyExpression, error) {
+	var operands []propertyExpression
+	for {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+
+		if !p.consumeKeyword("AND") {
+			break
+		}
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+
+	return propertyAndExpression{operands}, nil
+}
+
+func (p *propertyExpressionParser) parseUnary() (propertyExpression, error) {
+	if p.consumeKeyword("NOT") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return propertyNotExpression{operand}, nil
+	}
+
+	if p.consumeKeyword("EXISTS") {
+		name := p.scan("=!(),")
+		if name == "" {
+			return nil, p.errorf("expected property name after 'EXISTS'")
+		}
+		return propertyExistsExpression{name}, nil
+	}
+
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.consume(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *propertyExpressionParser) parseComparison() (propertyExpression, error) {
+	name := p.scan("=!(),")
+	if name == "" {
+		return nil, p.errorf("expected property name")
+	}
+
+	switch {
+	case p.consume("!="):
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return propertyNotExpression{propertyInExpression{name, []string{value}}}, nil
+
+	case p.consume("="):
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return propertyInExpression{name, []string{value}}, nil
+
+	case p.consumeKeyword("NOT"):
+		if !p.consumeKeyword("IN") {
+			return nil, p.errorf("expected 'IN' after 'NOT'")
+		}
+
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return propertyNotExpression{propertyInExpression{name, values}}, nil
+
+	case p.consumeKeyword("IN"):
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return propertyInExpression{name, values}, nil
+	}
+
+	return nil, p.errorf("expected '=', '!=' or 'IN' after property name '%s'", name)
+}
+
+func (p *propertyExpressionParser) parseValue() (string, error) {
+	p.skipWhitespace()
+
+	if strings.HasPrefix(p.input[p.pos:], `"`) {
+		quoted, err := strconv.QuotedPrefix(p.input[p.pos:])
+		if err != nil {
+			return "", p.errorf("unterminated quoted value")
+		}
+		p.pos += len(quoted)
+
+		return strconv.Unquote(quoted)
+	}
+
+	if strings.HasPrefix(p.input[p.pos:], "'") {
+		end := strings.IndexByte(p.input[p.pos+1:], '\'')
+		if end < 0 {
+			return "", p.errorf("unterminated quoted value")
+		}
+
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+
+		return value, nil
+	}
+
+	value := p.scan("(),")
+	if value == "" {
+		return "", p.errorf("expected value")
+	}
+
+	return value, nil
+}
+
+func (p *propertyExpressionParser) parseValueList() ([]string, error) {
+	if !p.consume("(") {
+		return nil, p.errorf("expected '(' after 'IN'")
+	}
+
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if p.consume(")") {
+			return values, nil
+		}
+
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ')'")
+		}
+	}
+}
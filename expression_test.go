@@ -0,0 +1,107 @@
+package reporter
+
+import (
+	"testing"
+)
+
+func TestPropertyExpression(t *testing.T) {
+	properties := map[string]string{
+		"platform": "baremetal",
+		"release":  "4.16",
+		"tier":     "nightly",
+		"config":   "a=10;b=20",
+		"owner":    "Network team",
+	}
+
+	expressions := map[string]bool{
+		"platform=baremetal":                                   true,
+		"platform = baremetal":                                 true,
+		"platform=baremetal AND release=4.16":                  true,
+		"platform=baremetal and release=4.15":                  false,
+		"platform=vsphere OR release=4.16":                     true,
+		"tier!=nightly":                                        false,
+		"missing!=nightly":                                     true,
+		"NOT tier=nightly":                                     false,
+		"platform IN (baremetal, vsphere)":                     true,
+		"platform in (aws,vsphere)":                            false,
+		"platform NOT IN (aws, vsphere)":                       true,
+		"missing NOT IN (aws)":                                 true,
+		"EXISTS release":                                       true,
+		"NOT EXISTS missing":                                   true,
+		"config=a=10;b=20":                                     true,
+		`owner="Network team"`:                                 true,
+		"owner='Network team' AND NOT (tier=nightly)":          false,
+		"(platform=aws OR platform=baremetal) AND EXISTS NOTE": false,
+		"platform=aws OR platform=baremetal AND tier=ci":       false,
+	}
+
+	for input, expected := range expressions {
+		expr, err := parsePropertyExpression(input)
+		if err != nil {
+			t.Fatalf("parsePropertyExpression failed for '%s': %s", input, err)
+		}
+
+		if result := expr.evaluate(properties); result != expected {
+			t.Fatalf("expression '%s' evaluated to %t, expected %t", input, result, expected)
+		}
+	}
+}
+
+func TestPropertyExpressionErrors(t *testing.T) {
+	invalidExpressions := []string{
+		"",
+		"=baremetal",
+		"EXISTS",
+		"release AND platform",
+		"platform=baremetal AND",
+		"platform=baremetal AND tier!=",
+		"(platform=baremetal",
+		"(platform=baremetal) AND NOT",
+		"platform IN baremetal",
+		"platform IN (baremetal",
+		"platform NOT baremetal",
+		`owner="Network team`,
+		"owner='Network team",
+	}
+
+	for _, input := range invalidExpressions {
+		if _, err := parsePropertyExpression(input); err == nil {
+			t.Fatalf("parsePropertyExpression should have failed for '%s'", input)
+		}
+	}
+}
+
+func TestPropertyExpressionLegacyRules(t *testing.T) {
+	properties := map[string]string{
+		"owner":       "Network team",
+		"description": "ptp (dual NIC), grandmaster",
+		"config":      "a=10;b=20",
+		"test-type":   "Functional and Performance",
+	}
+
+	// Rules written before the expressions were introduced keep their meaning
+	rules := map[string]bool{
+		"owner=Network team":                      true,
+		"owner=Storage team":                      false,
+		"description=ptp (dual NIC), grandmaster": true,
+		"config=a=10;b=20":                        true,
+		"platform=":                               true,
+		"owner=":                                  false,
+		"release":                                 false,
+		"platform=baremetal)":                     false,
+		"description=ptp (dual NIC), grandmaster (old)": false,
+		"test-type=Functional and Performance":          true,
+		"owner=Network and Storage team":                false,
+	}
+
+	for input, expected := range rules {
+		expr, err := parsePropertyExpression(input)
+		if err != nil {
+			t.Fatalf("parsePropertyExpression failed for '%s': %s", input, err)
+		}
+
+		if result := expr.evaluate(properties); result != expected {
+			t.Fatalf("rule '%s' evaluated to %t, expected %t", input, result, expected)
+		}
+	}
+}
@@ -0,0 +1,543 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"gopkg.in/yaml.v3"
+)
+
+// InputFormat represents a format of test reports that can be loaded by Reporter.
+type InputFormat string
+
+const (
+	// InputFormatAuto detects the format of each test report based on its extension and contents.
+	InputFormatAuto InputFormat = "auto"
+	// InputFormatJUnit represents JUnit XML test reports.
+	InputFormatJUnit InputFormat = "junit"
+	// InputFormatGoTest represents JSON streams produced by "go test -json" (test2json).
+	InputFormatGoTest InputFormat = "gotest"
+	// InputFormatTAP represents Test Anything Protocol streams.
+	InputFormatTAP InputFormat = "tap"
+	// InputFormatCucumber represents Cucumber JSON test reports.
+	InputFormatCucumber InputFormat = "cucumber"
+)
+
+// InputFormats lists all supported input formats.
+var InputFormats = []InputFormat{InputFormatAuto, InputFormatJUnit, InputFormatGoTest, InputFormatTAP, InputFormatCucumber}
+
+// SupportedInputFileExtensions lists extensions of files that are considered to be test reports.
+var SupportedInputFileExtensions = []string{".junit", ".xml", ".json", ".jsonl", ".tap"}
+
+// inputFormatExtensions lists extensions of files that are considered to be test reports in each input format.
+var inputFormatExtensions = map[InputFormat][]string{
+	InputFormatJUnit:    {".junit", ".xml"},
+	InputFormatGoTest:   {".json", ".jsonl"},
+	InputFormatTAP:      {".tap"},
+	InputFormatCucumber: {".json"},
+}
+
+// FileExtensions returns extensions of files that are considered to be test reports in the format.
+// All supported extensions are returned for InputFormatAuto.
+func (f InputFormat) FileExtensions() []string {
+	if extensions, ok := inputFormatExtensions[f]; ok {
+		return extensions
+	}
+
+	return SupportedInputFileExtensions
+}
+
+// Validate checks whether the format is one of the supported input formats.
+func (f InputFormat) Validate() error {
+	if f == "" || slices.Contains(InputFormats, f) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown input format '%s'. Expected one of: %v", f, InputFormats)
+}
+
+type inputParser func(name string, data []byte) ([]junit.Suite, error)
+
+var inputParsers = map[InputFormat]inputParser{
+	InputFormatJUnit:    parseJUnit,
+	InputFormatGoTest:   parseGoTest,
+	InputFormatTAP:      parseTAP,
+	InputFormatCucumber: parseCucumber,
+}
+
+// DetectInputFormat determines the format of a test report based on its file extension
+// and, if the extension is ambiguous, the beginning of its contents.
+func DetectInputFormat(path string, data []byte) (InputFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml", ".junit":
+		return InputFormatJUnit, nil
+	case ".tap":
+		return InputFormatTAP, nil
+	case ".jsonl":
+		return InputFormatGoTest, nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return InputFormatJUnit, nil
+	case bytes.HasPrefix(trimmed, []byte("[")) && bytes.Contains(trimmed, []byte(`"elements"`)):
+		return InputFormatCucumber, nil
+	case bytes.HasPrefix(trimmed, []byte("{")) && bytes.Contains(trimmed, []byte(`"Action"`)):
+		return InputFormatGoTest, nil
+	case tapLinePattern.Match(trimmed):
+		return InputFormatTAP, nil
+	}
+
+	return "", fmt.Errorf("format of test report '%s' could not be detected", path)
+}
+
+// IngestFiles loads test reports in a given format and converts them to JUnit Test Suites.
+// Paths can point to test reports, archives with test reports or the standard input (see readTestReports).
+// If the format is set to InputFormatAuto, the format is detected separately for each test report,
+// and test reports whose format cannot be detected are skipped. See IngestFilesFunc for large sets of test reports.
+func IngestFiles(paths []string, format InputFormat) ([]junit.Suite, error) {
+	var suites []junit.Suite
+	err := IngestFilesFunc(paths, format, 0, func(s []junit.Suite) error {
+		suites = append(suites, s...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suites, nil
+}
+
+// Ingest converts the contents of a single test report in a given format to JUnit Test Suites.
+// The name of the report is used for format detection and for naming Test Suites in formats
+// that do not define them.
+func Ingest(name string, data []byte, format InputFormat) ([]junit.Suite, error) {
+	if format == "" || format == InputFormatAuto {
+		var err error
+		if format, err = DetectInputFormat(name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	parse, ok := inputParsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown input format '%s'", format)
+	}
+
+	suites, err := parse(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("test report '%s' could not be loaded as %s: %w", name, format, err)
+	}
+
+	return suites, nil
+}
+
+func parseJUnit(_ string, data []byte) ([]junit.Suite, error) {
+	return junit.Ingest(data)
+}
+
+func suiteNameFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Go test2json format
+
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+type goTestResult struct {
+	test   junit.Test
+	output strings.Builder
+	done   bool
+}
+
+type goTestPackage struct {
+	name   string
+	tests  []*goTestResult
+	byName map[string]*goTestResult
+	output strings.Builder
+	failed bool
+}
+
+func parseGoTest(_ string, data []byte) ([]junit.Suite, error) {
+	var packages []*goTestPackage
+	packagesByName := map[string]*goTestPackage{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		pkg, ok := packagesByName[event.Package]
+		if !ok {
+			pkg = &goTestPackage{name: event.Package, byName: map[string]*goTestResult{}}
+			packagesByName[event.Package] = pkg
+			packages = append(packages, pkg)
+		}
+
+		if event.Test == "" {
+			if event.Action == "output" {
+				pkg.output.WriteString(event.Output)
+			} else if event.Action == "fail" {
+				pkg.failed = true
+			}
+			continue
+		}
+
+		result, ok := pkg.byName[event.Test]
+		if !ok {
+			result = &goTestResult{test: junit.Test{Name: event.Test, Classname: event.Package, Status: junit.StatusPassed}}
+			pkg.byName[event.Test] = result
+			pkg.tests = append(pkg.tests, result)
+		}
+
+		switch event.Action {
+		case "output":
+			result.output.WriteString(event.Output)
+		case "pass", "fail", "skip":
+			result.done = true
+			result.test.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			if event.Action == "fail" {
+				result.test.Status = junit.StatusFailed
+			} else if event.Action == "skip" {
+				result.test.Status = junit.StatusSkipped
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var suites []junit.Suite
+	for _, pkg := range packages {
+		suite := junit.Suite{Name: pkg.name, Package: pkg.name}
+
+		anyTestFailed := false
+		for _, result := range pkg.tests {
+			test := result.test
+			output := result.output.String()
+			test.SystemOut = output
+
+			if !result.done {
+				test.Status = junit.StatusError
+				test.Message = "Test did not finish"
+				test.Error = junit.Error{Message: test.Message, Body: output}
+			} else if test.Status == junit.StatusFailed {
+				test.Message = "Failed"
+				test.Error = junit.Error{Message: test.Message, Body: output}
+			} else if test.Status == junit.StatusSkipped {
+				test.Message = goTestSkipReason(output)
+			}
+
+			anyTestFailed = anyTestFailed || test.Status == junit.StatusFailed || test.Status == junit.StatusError
+			suite.Tests = append(suite.Tests, test)
+		}
+
+		// Failures outside of tests (e.g. build errors) are reported as a single errored Test Case
+		if pkg.failed && !anyTestFailed {
+			message := "Package failed"
+			suite.Tests = append(suite.Tests, junit.Test{
+				Name:      "(package)",
+				Classname: pkg.name,
+				Status:    junit.StatusError,
+				Message:   message,
+				Error:     junit.Error{Message: message, Body: pkg.output.String()},
+				SystemOut: pkg.output.String(),
+			})
+		}
+
+		suite.Aggregate()
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
+
+// goTestSkipReason extracts the message logged by t.Skip from the output of a skipped test.
+func goTestSkipReason(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- SKIP") {
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Test Anything Protocol (TAP) format
+
+var (
+	tapLinePattern      = regexp.MustCompile(`(?m)^(TAP version \d+|\d+\.\.\d+|(not )?ok\b)`)
+	tapTestPattern      = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:- )?([^#]*?)\s*(?:#\s*(.*))?$`)
+	tapDirectivePattern = regexp.MustCompile(`(?i)^(skip|todo)\S*\s*(.*)$`)
+)
+
+func parseTAP(name string, data []byte) ([]junit.Suite, error) {
+	suite := junit.Suite{Name: suiteNameFromPath(name)}
+
+	var diagnostics []string
+	inDiagnostics := false
+
+	// YAML diagnostics follow the test line they belong to
+	flushDiagnostics := func() {
+		if len(suite.Tests) == 0 || len(diagnostics) == 0 {
+			diagnostics = nil
+			return
+		}
+
+		test := &suite.Tests[len(suite.Tests)-1]
+		body := strings.Join(diagnostics, "\n")
+		diagnostics = nil
+
+		var fields struct {
+			Message string `yaml:"message"`
+		}
+		if err := yaml.Unmarshal([]byte(body), &fields); err == nil && fields.Message != "" && test.Message == "" {
+			test.Message = fields.Message
+		}
+
+		if test.Status == junit.StatusFailed {
+			test.Error = junit.Error{Message: test.Message, Body: body}
+		} else {
+			test.SystemOut = body
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if inDiagnostics {
+			if trimmed == "..." {
+				inDiagnostics = false
+				flushDiagnostics()
+			} else {
+				diagnostics = append(diagnostics, line)
+			}
+			continue
+		}
+
+		if trimmed == "---" && line != trimmed {
+			inDiagnostics = true
+			continue
+		}
+
+		// Indented lines belong to subtests, which are summarized by their parent test line
+		if line != strings.TrimLeft(line, " \t") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "Bail out!") {
+			message := strings.TrimSpace(strings.TrimPrefix(trimmed, "Bail out!"))
+			suite.Tests = append(suite.Tests, junit.Test{
+				Name:    "Bail out!",
+				Status:  junit.StatusError,
+				Message: message,
+				Error:   junit.Error{Message: message},
+			})
+			continue
+		}
+
+		m := tapTestPattern.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+
+		test := junit.Test{
+			Name:      m[3],
+			Classname: suite.Name,
+			Status:    junit.StatusPassed,
+		}
+		if test.Name == "" {
+			test.Name = fmt.Sprintf("test %s", m[2])
+		}
+		if m[1] != "" {
+			test.Status = junit.StatusFailed
+		}
+
+		if d := tapDirectivePattern.FindStringSubmatch(m[4]); d != nil {
+			if strings.EqualFold(d[1], "skip") {
+				test.Status = junit.StatusSkipped
+				test.Message = d[2]
+			} else if test.Status == junit.StatusFailed {
+				// Failing TODO tests are expected to fail and do not count as failures
+				test.Status = junit.StatusSkipped
+				test.Message = strings.TrimSpace("TODO " + d[2])
+			}
+		}
+
+		if test.Status == junit.StatusFailed {
+			test.Error = junit.Error{Message: test.Message}
+		}
+
+		suite.Tests = append(suite.Tests, test)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if inDiagnostics {
+		return nil, errors.New("unterminated YAML diagnostic block")
+	}
+
+	suite.Aggregate()
+
+	return []junit.Suite{suite}, nil
+}
+
+// Cucumber JSON format
+
+type cucumberTag struct {
+	Name string `json:"name"`
+}
+
+type cucumberStep struct {
+	Keyword string `json:"keyword"`
+	Name    string `json:"name"`
+	Result  struct {
+		Status       string `json:"status"`
+		Duration     int64  `json:"duration"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"result"`
+}
+
+type cucumberElement struct {
+	ID    string         `json:"id"`
+	Name  string         `json:"name"`
+	Type  string         `json:"type"`
+	Tags  []cucumberTag  `json:"tags"`
+	Steps []cucumberStep `json:"steps"`
+}
+
+type cucumberFeature struct {
+	URI      string            `json:"uri"`
+	Name     string            `json:"name"`
+	Tags     []cucumberTag     `json:"tags"`
+	Elements []cucumberElement `json:"elements"`
+}
+
+// cucumberTagProperties converts Cucumber tags to properties, so that they can be referenced by routing rules.
+// Each tag is stored as a property without a value, and all tags are listed in the "tags" property.
+func cucumberTagProperties(tags []cucumberTag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	properties := map[string]string{}
+	var names []string
+	for _, tag := range tags {
+		name := strings.TrimPrefix(tag.Name, "@")
+		properties[name] = ""
+		names = append(names, name)
+	}
+	properties["tags"] = strings.Join(names, ",")
+
+	return properties
+}
+
+var cucumberStatusSeverity = map[string]int{
+	"passed":    0,
+	"skipped":   1,
+	"pending":   2,
+	"undefined": 2,
+	"ambiguous": 2,
+	"failed":    3,
+}
+
+func parseCucumber(_ string, data []byte) ([]junit.Suite, error) {
+	var features []cucumberFeature
+	if err := json.Unmarshal(data, &features); err != nil {
+		return nil, err
+	}
+
+	var suites []junit.Suite
+	for _, feature := range features {
+		suite := junit.Suite{
+			Name:       feature.Name,
+			Package:    feature.URI,
+			Properties: cucumberTagProperties(feature.Tags),
+		}
+
+		for _, scenario := range feature.Elements {
+			if scenario.Type == "background" {
+				continue
+			}
+
+			test := junit.Test{
+				Name:       scenario.Name,
+				Classname:  feature.Name,
+				Status:     junit.StatusPassed,
+				Properties: cucumberTagProperties(scenario.Tags),
+			}
+
+			// The status of the scenario is given by its most severe step
+			worstStep := -1
+			var output []string
+			for i, step := range scenario.Steps {
+				test.Duration += time.Duration(step.Result.Duration)
+				output = append(output, fmt.Sprintf("%s%s (%s)", step.Keyword, step.Name, step.Result.Status))
+
+				if worstStep < 0 || cucumberStatusSeverity[step.Result.Status] > cucumberStatusSeverity[scenario.Steps[worstStep].Result.Status] {
+					worstStep = i
+				}
+			}
+			test.SystemOut = strings.Join(output, "\n")
+
+			if worstStep >= 0 {
+				step := scenario.Steps[worstStep]
+				switch step.Result.Status {
+				case "failed":
+					test.Status = junit.StatusFailed
+				case "pending", "undefined", "ambiguous":
+					test.Status = junit.StatusError
+				case "skipped":
+					test.Status = junit.StatusSkipped
+				}
+
+				if test.Status != junit.StatusPassed {
+					test.Message = fmt.Sprintf("Step '%s%s' is %s", step.Keyword, step.Name, step.Result.Status)
+				}
+				if test.Status == junit.StatusFailed || test.Status == junit.StatusError {
+					test.Error = junit.Error{Message: test.Message, Body: step.Result.ErrorMessage}
+				}
+			}
+
+			suite.Tests = append(suite.Tests, test)
+		}
+
+		suite.Aggregate()
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+)
+
+func TestDetectInputFormat(t *testing.T) {
+	expectedFormats := map[string]InputFormat{
+		"testdata/valid/simple.xml":    InputFormatJUnit,
+		"testdata/valid/gotest.json":   InputFormatGoTest,
+		"testdata/valid/simple.tap":    InputFormatTAP,
+		"testdata/valid/cucumber.json": InputFormatCucumber,
+	}
+
+	for path, expected := range expectedFormats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("test report could not be read: %s", err)
+		}
+
+		// Detection by contents only
+		format, err := DetectInputFormat("report", data)
+		if err != nil {
+			t.Fatalf("DetectInputFormat failed for '%s': %s", path, err)
+		}
+
+		if format != expected {
+			t.Fatalf("expected format of '%s' to be %s, got %s", path, expected, format)
+		}
+	}
+
+	if _, err := DetectInputFormat("report.json", []byte(`{"key": "value"}`)); err == nil {
+		t.Fatalf("DetectInputFormat should have failed for an unknown JSON file")
+	}
+}
+
+func TestIngestFiles(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected []junit.Totals
+	}{
+		{
+			path: "testdata/valid/gotest.json",
+			expected: []junit.Totals{
+				{Tests: 3, Passed: 1, Failed: 1, Skipped: 1},
+				{Tests: 1, Error: 1},
+			},
+		},
+		{
+			path:     "testdata/valid/simple.tap",
+			expected: []junit.Totals{{Tests: 5, Passed: 2, Failed: 1, Skipped: 2}},
+		},
+		{
+			path:     "testdata/valid/cucumber.json",
+			expected: []junit.Totals{{Tests: 3, Passed: 1, Failed: 1, Error: 1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		suites, err := IngestFiles([]string{tc.path}, InputFormatAuto)
+		if err != nil {
+			t.Fatalf("IngestFiles failed for '%s': %s", tc.path, err)
+		}
+
+		if len(suites) != len(tc.expected) {
+			t.Fatalf("expected %d Test Suites in '%s', got %d", len(tc.expected), tc.path, len(suites))
+		}
+
+		for i, suite := range suites {
+			totals := suite.Totals
+			totals.Duration = 0
+			if totals != tc.expected[i] {
+				t.Fatalf("expected totals %+v for Test Suite '%s', got %+v", tc.expected[i], suite.Name, totals)
+			}
+		}
+	}
+}
+
+func TestIngestedTestCaseDetails(t *testing.T) {
+	suites, err := IngestFiles([]string{"testdata/valid/gotest.json", "testdata/valid/simple.tap", "testdata/valid/cucumber.json"}, InputFormatAuto)
+	if err != nil {
+		t.Fatalf("IngestFiles failed: %s", err)
+	}
+
+	goTestSkipped := NewTestCase(suites[0].Tests[2])
+	if goTestSkipped.SkipReason != "pkg_test.go:20: not supported on this platform" {
+		t.Fatalf("unexpected skip reason: '%s'", goTestSkipped.SkipReason)
+	}
+
+	tapFailed := NewTestCase(suites[2].Tests[1])
+	if tapFailed.Name != "backup can be scheduled" || tapFailed.Message != "backup job did not start" {
+		t.Fatalf("unexpected TAP failure: %+v", tapFailed)
+	}
+
+	cucumberFailed := NewTestCase(suites[3].Tests[1])
+	if cucumberFailed.StackTrace != "expected an error message" {
+		t.Fatalf("unexpected Cucumber failure: %+v", cucumberFailed)
+	}
+
+	if _, ok := suites[3].Tests[0].Properties["smoke"]; !ok || suites[3].Properties["tags"] != "auth" {
+		t.Fatalf("Cucumber tags were not converted to properties")
+	}
+}
+
+func TestIngestFilesWithExplicitFormat(t *testing.T) {
+	if _, err := IngestFiles([]string{"testdata/valid/simple.tap"}, InputFormatCucumber); err == nil {
+		t.Fatalf("IngestFiles should have failed for a test report in a different format")
+	}
+
+	if _, err := IngestFiles([]string{"testdata/valid/missing.xml"}, InputFormatJUnit); err == nil {
+		t.Fatalf("IngestFiles should have failed for a missing test report")
+	}
+}
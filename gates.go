@@ -0,0 +1,100 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+)
+
+// QualityGateOverallScope is the scope of violations of the gates evaluated on all reports together.
+const QualityGateOverallScope = "overall"
+
+// QualityGateViolation describes a quality gate that has not been met either by a single report
+// (the scope is its destination) or by all reports together (the scope is QualityGateOverallScope).
+type QualityGateViolation struct {
+	Scope   string `json:"scope" yaml:"scope"`
+	Gate    string `json:"gate" yaml:"gate"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (v QualityGateViolation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.Scope, v.Gate, v.Message)
+}
+
+// Validate checks whether the thresholds of the quality gates are within their allowed ranges.
+func (c QualityGatesConfig) Validate() error {
+	gates := []struct {
+		scope string
+		gate  QualityGateConfig
+	}{{"overall", c.Overall}, {"perReport", c.PerReport}}
+
+	for _, g := range gates {
+		scope, gate := g.scope, g.gate
+		if gate.MinPassRate < 0 || gate.MinPassRate > 100 {
+			return fmt.Errorf("%s.minPassRate: expected a percentage between 0 and 100, got %g", scope, gate.MinPassRate)
+		}
+
+		if gate.MaxFailed != nil && *gate.MaxFailed < 0 {
+			return fmt.Errorf("%s.maxFailed: expected a non-negative number, got %d", scope, *gate.MaxFailed)
+		}
+	}
+
+	return nil
+}
+
+// EvaluateQualityGates checks the quality gates on each report and on all reports together.
+// Violations are returned in the order of the reports, followed by the violations of the overall gates.
+func EvaluateQualityGates(reports []AggregateReport, config QualityGatesConfig) (violations []QualityGateViolation) {
+	var overall Counts
+	var overallSuites []string
+
+	for _, report := range reports {
+		var suites []string
+		for _, suite := range report.TestSuites {
+			suites = append(suites, suite.Name)
+		}
+
+		violations = append(violations, config.PerReport.evaluate(report.Destination, report.Counts, suites)...)
+
+		overall.Passed += report.Counts.Passed
+		overall.Failed += report.Counts.Failed
+		overall.Errored += report.Counts.Errored
+		overall.Skipped += report.Counts.Skipped
+		overall.Flaky += report.Counts.Flaky
+		overall.Total += report.Counts.Total
+		overallSuites = append(overallSuites, suites...)
+	}
+
+	return append(violations, config.Overall.evaluate(QualityGateOverallScope, overall, overallSuites)...)
+}
+
+// evaluate checks the gates on test counts and Test Suites of a given scope. Gates that are not set always pass.
+func (g QualityGateConfig) evaluate(scope string, counts Counts, suites []string) (violations []QualityGateViolation) {
+	violate := func(gate string, format string, a ...any) {
+		violations = append(violations, QualityGateViolation{Scope: scope, Gate: gate, Message: fmt.Sprintf(format, a...)})
+	}
+
+	// Skipped Test Cases are neither passed nor failed, so they are left out of the pass rate
+	if g.MinPassRate > 0 {
+		if counts.Executed() == 0 {
+			violate("minPassRate", "no test cases have been executed")
+		} else if passRate := counts.PassRate(); passRate < g.MinPassRate {
+			violate("minPassRate", "pass rate %.1f%% is below %g%%", passRate, g.MinPassRate)
+		}
+	}
+
+	if g.MaxFailed != nil && counts.Failed > *g.MaxFailed {
+		violate("maxFailed", "%d test cases failed, at most %d allowed", counts.Failed, *g.MaxFailed)
+	}
+
+	if g.NoErrored && counts.Errored > 0 {
+		violate("noErrored", "%d test cases errored", counts.Errored)
+	}
+
+	for _, name := range g.RequiredSuites {
+		if !slices.Contains(suites, name) {
+			violate("requiredSuites", "test suite '%s' is missing", name)
+		}
+	}
+
+	return violations
+}
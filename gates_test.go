@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEvaluateQualityGates(t *testing.T) {
+	passed := AggregateReport{
+		Destination: "EXAMPLE-15",
+		TestSuites:  []TestSuite{{Name: "Networking", Counts: Counts{Passed: 9, Skipped: 1, Total: 10}}},
+	}
+	passed.AggregateCounts()
+
+	failed := AggregateReport{
+		Destination: "EXAMPLE-20",
+		TestSuites:  []TestSuite{{Name: "Storage", Counts: Counts{Passed: 6, Failed: 2, Errored: 1, Skipped: 1, Total: 10}}},
+	}
+	failed.AggregateCounts()
+
+	zero := 0
+	config := QualityGatesConfig{
+		Overall:   QualityGateConfig{MinPassRate: 80, RequiredSuites: []string{"Networking", "Storage", "Compute"}},
+		PerReport: QualityGateConfig{MaxFailed: &zero, NoErrored: true},
+	}
+
+	var violations []string
+	for _, v := range EvaluateQualityGates([]AggregateReport{passed, failed}, config) {
+		violations = append(violations, v.String())
+	}
+
+	// The overall pass rate is 15 out of 18 executed test cases
+	expected := []string{
+		"EXAMPLE-20: maxFailed: 2 test cases failed, at most 0 allowed",
+		"EXAMPLE-20: noErrored: 1 test cases errored",
+		"overall: requiredSuites: test suite 'Compute' is missing",
+	}
+	if !slices.Equal(violations, expected) {
+		t.Fatalf("expected violations %q, got %q", expected, violations)
+	}
+
+	config.Overall.MinPassRate = 90
+	violations = nil
+	for _, v := range EvaluateQualityGates([]AggregateReport{passed, failed}, QualityGatesConfig{Overall: config.Overall}) {
+		violations = append(violations, v.String())
+	}
+
+	if len(violations) != 2 || violations[0] != "overall: minPassRate: pass rate 83.3% is below 90%" {
+		t.Fatalf("expected the pass rate gate to fail, got %q", violations)
+	}
+
+	if violations := EvaluateQualityGates(nil, QualityGatesConfig{}); len(violations) != 0 {
+		t.Fatalf("expected gates that are not set to pass, got %v", violations)
+	}
+}
+
+func TestQualityGatesConfigValidate(t *testing.T) {
+	negative := -1
+	testCases := map[string]QualityGatesConfig{
+		"overall.minPassRate: expected a percentage between 0 and 100, got 120": {Overall: QualityGateConfig{MinPassRate: 120}},
+		"perReport.maxFailed: expected a non-negative number, got -1":           {PerReport: QualityGateConfig{MaxFailed: &negative}},
+	}
+
+	for expected, config := range testCases {
+		if err := config.Validate(); err == nil || err.Error() != expected {
+			t.Fatalf("expected error '%s', got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package reporter
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/joshdk/go-junit"
+)
+
+// errIngestionStopped is returned internally when reading of test reports is interrupted.
+var errIngestionStopped = errors.New("ingestion stopped")
+
+type ingestionJob struct {
+	seq  int
+	path string
+	name string
+	data []byte
+	err  error
+}
+
+type ingestionResult struct {
+	seq     int
+	path    string
+	suites  []junit.Suite
+	skipped error
+	err     error
+}
+
+// IngestFilesFunc loads test reports in the same way as IngestFiles, but instead of returning all Test Suites
+// at once, it passes the Test Suites of each test report to a callback function as soon as they are available.
+// Test reports are parsed concurrently by a given number of workers (the number of CPUs if not positive), while
+// the callback is always called sequentially and in the same order as the test reports are read. At most twice
+// as many test reports as there are workers are kept in memory at the same time. Processing stops at the first
+// error, either returned by the callback or encountered while reading or parsing the test reports.
+func IngestFilesFunc(paths []string, format InputFormat, workers int, fn func(suites []junit.Suite) error) error {
+	return ingestFiles(paths, format, workers, func(_ string, suites []junit.Suite) error { return fn(suites) })
+}
+
+// ingestFiles works like IngestFilesFunc, passing the path each test report has been read from to the callback
+// along with its Test Suites. Test reports found in archives share the path of the archive.
+func ingestFiles(paths []string, format InputFormat, workers int, fn func(path string, suites []junit.Suite) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan ingestionJob)
+	results := make(chan ingestionResult)
+	slots := make(chan struct{}, 2*workers)
+	done := make(chan struct{})
+	defer close(done)
+
+	// Read test reports sequentially and hand them over to the workers
+	go func() {
+		defer close(jobs)
+
+		seq := 0
+		send := func(job ingestionJob) error {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return errIngestionStopped
+			}
+
+			select {
+			case jobs <- job:
+				seq++
+				return nil
+			case <-done:
+				return errIngestionStopped
+			}
+		}
+
+		for _, path := range paths {
+			err := readTestReports(path, format, func(name string, data []byte) error {
+				return send(ingestionJob{seq: seq, path: path, name: name, data: data})
+			})
+			if err != nil {
+				// Read errors are reported in order, after all previously read test reports are processed
+				if !errors.Is(err, errIngestionStopped) {
+					send(ingestionJob{seq: seq, err: err})
+				}
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := ingestionResult{seq: job.seq, path: job.path, err: job.err}
+				if job.err == nil {
+					result.suites, result.skipped, result.err = ingestTestReport(job.name, job.data, format)
+				}
+
+				select {
+				case results <- result:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Reorder results, so that the callback sees test reports in the order they were read
+	pending := map[int]ingestionResult{}
+	next := 0
+	for result := range results {
+		pending[result.seq] = result
+
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+
+			if r.err != nil {
+				return r.err
+			}
+
+			if r.skipped != nil {
+				WarnLog.Printf("%s. Skipping", r.skipped)
+				continue
+			}
+
+			if err := fn(r.path, r.suites); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ingestTestReport converts a single test report to JUnit Test Suites. If the format is set to InputFormatAuto
+// and the format of the test report cannot be detected, the reason is returned as skipped instead of an error.
+func ingestTestReport(name string, data []byte, format InputFormat) (suites []junit.Suite, skipped error, err error) {
+	if format == "" || format == InputFormatAuto {
+		if format, skipped = DetectInputFormat(name, data); skipped != nil {
+			return nil, skipped, nil
+		}
+	}
+
+	suites, err = Ingest(name, data, format)
+
+	return suites, nil, err
+}
@@ -0,0 +1,148 @@
+package reporter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+)
+
+// generateJUnitReports writes a given number of JUnit test reports to a temporary directory and returns their paths.
+// Every tenth Test Case fails, and each Test Suite has a "component" property cycling through five values.
+func generateJUnitReports(tb testing.TB, files int, suitesPerFile int, testsPerSuite int) []string {
+	dir := tb.TempDir()
+
+	var paths []string
+	for f := range files {
+		var sb strings.Builder
+		sb.WriteString("<testsuites>\n")
+		for s := range suitesPerFile {
+			suite := f*suitesPerFile + s
+			fmt.Fprintf(&sb, "  <testsuite name=\"suite-%d\">\n", suite)
+			fmt.Fprintf(&sb, "    <properties><property name=\"component\" value=\"component-%d\"/></properties>\n", suite%5)
+			for t := range testsPerSuite {
+				fmt.Fprintf(&sb, "    <testcase name=\"test-%d\" classname=\"suite-%d\" time=\"0.01\">", t, suite)
+				if t%10 == 0 {
+					sb.WriteString("<failure message=\"assertion failed\">expected true, got false</failure>")
+				}
+				sb.WriteString("</testcase>\n")
+			}
+			sb.WriteString("  </testsuite>\n")
+		}
+		sb.WriteString("</testsuites>\n")
+
+		path := filepath.Join(dir, fmt.Sprintf("report-%04d.xml", f))
+		if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+			tb.Fatalf("test report could not be written: %s", err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+func TestIngestFilesFuncKeepsOrder(t *testing.T) {
+	paths := generateJUnitReports(t, 50, 2, 5)
+
+	for _, workers := range []int{1, 4, 16} {
+		var names []string
+		err := IngestFilesFunc(paths, InputFormatAuto, workers, func(suites []junit.Suite) error {
+			for _, suite := range suites {
+				names = append(names, suite.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("IngestFilesFunc failed with %d workers: %s", workers, err)
+		}
+
+		if len(names) != 100 {
+			t.Fatalf("expected 100 Test Suites with %d workers, got %d", workers, len(names))
+		}
+
+		for i, name := range names {
+			if name != fmt.Sprintf("suite-%d", i) {
+				t.Fatalf("expected Test Suite %d to be 'suite-%d' with %d workers, got '%s'", i, i, workers, name)
+			}
+		}
+	}
+}
+
+func TestIngestFilesFuncStopsAtFirstError(t *testing.T) {
+	paths := generateJUnitReports(t, 20, 1, 1)
+	paths = append(paths[:10], append([]string{"testdata/valid/missing.xml"}, paths[10:]...)...)
+
+	calls := 0
+	err := IngestFilesFunc(paths, InputFormatAuto, 4, func(suites []junit.Suite) error {
+		calls++
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("IngestFilesFunc should have failed for a missing test report")
+	}
+
+	if calls != 10 {
+		t.Fatalf("expected all 10 test reports preceding the missing one to be processed, got %d", calls)
+	}
+
+	errCallback := errors.New("callback failed")
+	err = IngestFilesFunc(paths[:10], InputFormatAuto, 4, func(suites []junit.Suite) error {
+		return errCallback
+	})
+	if !errors.Is(err, errCallback) {
+		t.Fatalf("expected the error returned by the callback, got %v", err)
+	}
+}
+
+func BenchmarkIngestFiles(b *testing.B) {
+	paths := generateJUnitReports(b, 200, 5, 50)
+
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				err := IngestFilesFunc(paths, InputFormatJUnit, workers, func(suites []junit.Suite) error { return nil })
+				if err != nil {
+					b.Fatalf("IngestFilesFunc failed: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProcessJUnitReports(b *testing.B) {
+	paths := generateJUnitReports(b, 200, 5, 50)
+
+	// One route per Test Suite with its own destination, matched by name, and a few routes matched by properties
+	// and patterns. The time spent should not grow with the number of routes matched by name
+	for _, routes := range []int{100, 1000, 10000} {
+		var routing []ReportingRouteConfig
+		for s := range routes {
+			routing = append(routing, ReportingRouteConfig{
+				Destination: fmt.Sprintf("EXAMPLE-%d", s),
+				TestSuites:  []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: fmt.Sprintf("suite-%d", s)}}},
+			})
+		}
+		for c := range 5 {
+			routing = append(routing, ReportingRouteConfig{
+				Destination: fmt.Sprintf("COMPONENT-%d", c),
+				TestSuites: []ReportingTestSuiteConfig{{
+					ReportingRuleConfig: ReportingRuleConfig{Property: fmt.Sprintf("component=component-%d", c)},
+					TestCases:           []ReportingTestCaseConfig{{ReportingRuleConfig{NameGlob: "test-1*"}}},
+				}},
+			})
+		}
+
+		b.Run(fmt.Sprintf("routes=%d", routes), func(b *testing.B) {
+			for range b.N {
+				config := ReportingConfig{Routing: routing}
+				if _, err := ProcessJUnitReports(paths, config); err != nil {
+					b.Fatalf("ProcessJUnitReports failed: %s", err)
+				}
+			}
+		})
+	}
+}
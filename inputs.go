@@ -0,0 +1,234 @@
+package reporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// StdinPath represents the standard input when given as a path to a test report.
+const StdinPath = "-"
+
+// SupportedArchiveExtensions lists extensions of archives and compressed files that can contain test reports.
+var SupportedArchiveExtensions = []string{".tar", ".tar.gz", ".tgz", ".zip", ".gz"}
+
+// stdin is read when the StdinPath is given as a path to a test report. It can be replaced in tests.
+var stdin io.Reader = os.Stdin
+
+// stdinContents keeps the contents of stdin, as it can only be read once, but the test report
+// given on stdin is read again when it's attached to Jira issues.
+var stdinContents struct {
+	sync.Mutex
+	source io.Reader
+	data   []byte
+	err    error
+}
+
+// readStdin reads the whole standard input. Subsequent calls return the same contents.
+func readStdin() ([]byte, error) {
+	stdinContents.Lock()
+	defer stdinContents.Unlock()
+
+	if stdinContents.source != stdin {
+		stdinContents.source = stdin
+		stdinContents.data, stdinContents.err = io.ReadAll(stdin)
+	}
+
+	return stdinContents.data, stdinContents.err
+}
+
+var gzipMagicNumber = []byte{0x1f, 0x8b}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsSupportedInputFile checks whether a file with a given name is a test report,
+// or an archive that can contain test reports. Files compressed with gzip are supported if they are test reports.
+func IsSupportedInputFile(name string) bool {
+	return InputFormatAuto.IsSupportedInputFile(name)
+}
+
+// IsSupportedInputFile checks whether a file with a given name is a test report in the format,
+// or an archive that can contain test reports. Files compressed with gzip are supported if they are test reports.
+func (f InputFormat) IsSupportedInputFile(name string) bool {
+	extensions := f.FileExtensions()
+	if uncompressed, ok := strings.CutSuffix(strings.ToLower(name), ".gz"); ok && !strings.HasSuffix(uncompressed, ".tar") {
+		return hasAnySuffix(uncompressed, extensions)
+	}
+
+	return hasAnySuffix(name, extensions) || hasAnySuffix(name, SupportedArchiveExtensions)
+}
+
+// isSupportedArchiveMember checks whether a file found in an archive is a test report in a given format.
+// Hidden files, such as metadata added by some archivers, are skipped.
+func isSupportedArchiveMember(name string, format InputFormat) bool {
+	return hasAnySuffix(name, format.FileExtensions()) && !strings.HasPrefix(path.Base(name), ".")
+}
+
+// readTestReports reads test reports from a given path and passes their contents to a callback function.
+// Archives (.tar, .tar.gz, .tgz and .zip) are expanded and all test reports found inside are read in the
+// order they are stored in. Test reports compressed with gzip (.gz) are decompressed, and the StdinPath reads
+// a single, optionally gzip-compressed, test report from the standard input.
+// Test reports found in archives are named after the archive and their path within the archive. Only archive
+// members and compressed files with the extensions of a given format are read.
+func readTestReports(p string, format InputFormat, fn func(name string, data []byte) error) error {
+	lower := strings.ToLower(p)
+
+	switch {
+	case p == StdinPath:
+		data, err := readStdin()
+		if err != nil {
+			return fmt.Errorf("test report could not be read from stdin: %w", err)
+		}
+
+		if bytes.HasPrefix(data, gzipMagicNumber) {
+			if data, err = gunzip(bytes.NewReader(data)); err != nil {
+				return fmt.Errorf("test report could not be read from stdin: %w", err)
+			}
+		}
+
+		return fn("stdin", data)
+
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("archive '%s' could not be read: %w", p, err)
+		}
+		defer gz.Close()
+
+		return readTarMembers(p, gz, format, fn)
+
+	case strings.HasSuffix(lower, ".tar"):
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return readTarMembers(p, f, format, fn)
+
+	case strings.HasSuffix(lower, ".zip"):
+		return readZipMembers(p, format, fn)
+
+	case strings.HasSuffix(lower, ".gz"):
+		// Like archive members, only compressed test reports are read, e.g. compressed logs are skipped
+		name := p[:len(p)-len(".gz")]
+		if !isSupportedArchiveMember(name, format) {
+			WarnLog.Printf("File '%s' is not a compressed test report. Skipping", p)
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		data, err := gunzip(f)
+		if err != nil {
+			return fmt.Errorf("file '%s' could not be decompressed: %w", p, err)
+		}
+
+		return fn(name, data)
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+
+	return fn(p, data)
+}
+
+func gunzip(r io.Reader) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	return io.ReadAll(gz)
+}
+
+func readTarMembers(archivePath string, r io.Reader, format InputFormat, fn func(name string, data []byte) error) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("archive '%s' could not be read: %w", archivePath, err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !isSupportedArchiveMember(hdr.Name, format) {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("file '%s' could not be read from archive '%s': %w", hdr.Name, archivePath, err)
+		}
+
+		if err := fn(archiveMemberName(archivePath, hdr.Name), data); err != nil {
+			return err
+		}
+	}
+}
+
+func readZipMembers(archivePath string, format InputFormat, fn func(name string, data []byte) error) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("archive '%s' could not be read: %w", archivePath, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !isSupportedArchiveMember(f.Name, format) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("file '%s' could not be read from archive '%s': %w", f.Name, archivePath, err)
+		}
+
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("file '%s' could not be read from archive '%s': %w", f.Name, archivePath, err)
+		}
+
+		if err := fn(archiveMemberName(archivePath, f.Name), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func archiveMemberName(archivePath string, member string) string {
+	return fmt.Sprintf("%s:%s", archivePath, member)
+}
@@ -0,0 +1,203 @@
+package reporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func createTestArchives(t *testing.T, members map[string]string) string {
+	dir := t.TempDir()
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var tarData bytes.Buffer
+	tw := tar.NewWriter(&tarData)
+	for _, name := range names {
+		data, err := os.ReadFile(members[name])
+		if err != nil {
+			t.Fatalf("test report could not be read: %s", err)
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+		tw.Write(data)
+	}
+	tw.Close()
+
+	var tgzData bytes.Buffer
+	gw := gzip.NewWriter(&tgzData)
+	gw.Write(tarData.Bytes())
+	gw.Close()
+
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for _, name := range names {
+		data, _ := os.ReadFile(members[name])
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	zw.Close()
+
+	os.WriteFile(filepath.Join(dir, "reports.tar"), tarData.Bytes(), 0o644)
+	os.WriteFile(filepath.Join(dir, "reports.tar.gz"), tgzData.Bytes(), 0o644)
+	os.WriteFile(filepath.Join(dir, "reports.tgz"), tgzData.Bytes(), 0o644)
+	os.WriteFile(filepath.Join(dir, "reports.zip"), zipData.Bytes(), 0o644)
+
+	return dir
+}
+
+func TestReadTestReportsFromArchives(t *testing.T) {
+	dir := createTestArchives(t, map[string]string{
+		"results/simple.xml":   "testdata/valid/simple.xml",
+		"results/simple.tap":   "testdata/valid/simple.tap",
+		"results/._simple.xml": "testdata/valid/simple.xml",
+		"results/README.md":    "testdata/valid/simple.tap",
+	})
+
+	for _, archive := range []string{"reports.tar", "reports.tar.gz", "reports.tgz", "reports.zip"} {
+		path := filepath.Join(dir, archive)
+
+		var names []string
+		err := readTestReports(path, InputFormatAuto, func(name string, data []byte) error {
+			names = append(names, name)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("readTestReports failed for '%s': %s", archive, err)
+		}
+
+		expected := []string{path + ":results/simple.tap", path + ":results/simple.xml"}
+		if !slices.Equal(names, expected) {
+			t.Fatalf("expected test reports %v in '%s', got %v", expected, archive, names)
+		}
+
+		// Only the members with the extensions of an explicitly selected format are read
+		names = nil
+		err = readTestReports(path, InputFormatJUnit, func(name string, data []byte) error {
+			names = append(names, name)
+			return nil
+		})
+		if err != nil || !slices.Equal(names, []string{path + ":results/simple.xml"}) {
+			t.Fatalf("expected only JUnit test reports to be read from '%s', got %v (%v)", archive, names, err)
+		}
+
+		suites, err := IngestFiles([]string{path}, InputFormatAuto)
+		if err != nil {
+			t.Fatalf("IngestFiles failed for '%s': %s", archive, err)
+		}
+
+		if len(suites) != 2 {
+			t.Fatalf("expected 2 Test Suites in '%s', got %d", archive, len(suites))
+		}
+	}
+}
+
+func TestReadTestReportsFromGzipAndStdin(t *testing.T) {
+	data, err := os.ReadFile("testdata/valid/simple.xml")
+	if err != nil {
+		t.Fatalf("test report could not be read: %s", err)
+	}
+
+	var gzData bytes.Buffer
+	gw := gzip.NewWriter(&gzData)
+	gw.Write(data)
+	gw.Close()
+
+	path := filepath.Join(t.TempDir(), "simple.xml.gz")
+	os.WriteFile(path, gzData.Bytes(), 0o644)
+
+	err = readTestReports(path, InputFormatAuto, func(name string, d []byte) error {
+		if name != path[:len(path)-len(".gz")] || !bytes.Equal(d, data) {
+			t.Fatalf("unexpected test report '%s' read from '%s'", name, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readTestReports failed for '%s': %s", path, err)
+	}
+
+	// Other compressed files are skipped, even if their path is given explicitly
+	logPath := filepath.Join(t.TempDir(), "build.log.gz")
+	os.WriteFile(logPath, gzData.Bytes(), 0o644)
+
+	err = readTestReports(logPath, InputFormatAuto, func(name string, d []byte) error {
+		t.Fatalf("unexpected test report '%s' read from '%s'", name, logPath)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readTestReports failed for '%s': %s", logPath, err)
+	}
+
+	originalStdin := stdin
+	defer func() { stdin = originalStdin }()
+
+	for _, input := range [][]byte{data, gzData.Bytes()} {
+		f, err := os.CreateTemp(t.TempDir(), "stdin")
+		if err != nil {
+			t.Fatalf("stdin could not be created: %s", err)
+		}
+		f.Write(input)
+		f.Seek(0, 0)
+		stdin = f
+
+		suites, err := IngestFiles([]string{StdinPath}, InputFormatAuto)
+		if err != nil {
+			t.Fatalf("IngestFiles failed for stdin: %s", err)
+		}
+
+		if len(suites) != 1 {
+			t.Fatalf("expected 1 Test Suite read from stdin, got %d", len(suites))
+		}
+	}
+}
+
+func TestIsSupportedInputFile(t *testing.T) {
+	for _, name := range []string{"report.xml", "report.json", "reports.tar", "reports.TGZ", "reports.tar.gz", "reports.zip", "report.xml.gz"} {
+		if !IsSupportedInputFile(name) {
+			t.Fatalf("expected '%s' to be a supported input file", name)
+		}
+	}
+
+	for _, name := range []string{"build.log", "build.log.gz", "reports.7z", "README.md"} {
+		if IsSupportedInputFile(name) {
+			t.Fatalf("expected '%s' not to be a supported input file", name)
+		}
+	}
+
+	// Files are filtered by the extensions of an explicitly selected format
+	supported := map[InputFormat][]string{
+		InputFormatJUnit:    {"report.xml", "report.junit", "report.xml.gz", "reports.tar"},
+		InputFormatGoTest:   {"report.json", "report.jsonl"},
+		InputFormatTAP:      {"report.tap"},
+		InputFormatCucumber: {"report.json"},
+	}
+	unsupported := map[InputFormat][]string{
+		InputFormatJUnit:    {"report.json", "report.tap", "report.json.gz"},
+		InputFormatGoTest:   {"report.xml"},
+		InputFormatTAP:      {"report.json"},
+		InputFormatCucumber: {"report.jsonl", "report.xml"},
+	}
+
+	for format, names := range supported {
+		for _, name := range names {
+			if !format.IsSupportedInputFile(name) {
+				t.Fatalf("expected '%s' to be a supported input file in the %s format", name, format)
+			}
+		}
+	}
+
+	for format, names := range unsupported {
+		for _, name := range names {
+			if format.IsSupportedInputFile(name) {
+				t.Fatalf("expected '%s' not to be a supported input file in the %s format", name, format)
+			}
+		}
+	}
+}
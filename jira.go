@@ -2,24 +2,151 @@ package reporter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 	"slices"
 	"strings"
+	"time"
 )
 
 const (
-	jiraIssuesEndpoint = "/rest/api/2/issue/"
+	jiraIssuesEndpoint         = "/rest/api/2/issue/"
+	jiraCloudIssuesEndpoint    = "/rest/api/3/issue/"
+	jiraIssueLinkEndpoint      = "/rest/api/2/issueLink"
+	jiraCloudIssueLinkEndpoint = "/rest/api/3/issueLink"
 )
 
+// JiraFlavor defines which variant of Jira the client communicates with.
+type JiraFlavor string
+
+const (
+	// JiraFlavorDataCenter uses the REST API v2 with Personal Access Tokens and the Jira wiki markup.
+	JiraFlavorDataCenter JiraFlavor = "datacenter"
+	// JiraFlavorCloud uses the REST API v3 with basic authentication (email and API token)
+	// and the Atlassian Document Format (ADF).
+	JiraFlavorCloud JiraFlavor = "cloud"
+)
+
+// Validate checks whether the flavor is one of the supported Jira flavors.
+func (f JiraFlavor) Validate() error {
+	switch f {
+	case "", JiraFlavorDataCenter, JiraFlavorCloud:
+		return nil
+	}
+
+	return fmt.Errorf("unknown Jira flavor '%s'. Expected one of: %s, %s", f, JiraFlavorDataCenter, JiraFlavorCloud)
+}
+
+// JiraAPI describes the operations of the Jira REST API required for uploading test results.
+// It is implemented by JiraClient, and can be implemented by other types, for example to
+// add caching or to test uploads without a Jira server. Optional features of the desired state
+// are only available if the implementation also provides the operations they use, see
+// JiraCommentsAPI, JiraAttachmentsAPI, JiraTransitionsAPI and JiraSearchAPI.
+type JiraAPI interface {
+	GetIssue(ctx context.Context, id string) (JiraIssue, error)
+	UpdateIssue(ctx context.Context, id string, summary string, description string, labels []string) error
+	CreateSubtask(ctx context.Context, parent string, summary string, description string, labels []string) (string, error)
+}
+
+// JiraCommentsAPI describes the operations used to post and prune comments.
+type JiraCommentsAPI interface {
+	JiraAPI
+	GetCurrentUser(ctx context.Context) (JiraUser, error)
+	GetComments(ctx context.Context, id string) ([]JiraComment, error)
+	AddComment(ctx context.Context, id string, body string) error
+	DeleteComment(ctx context.Context, id string, commentID string) error
+}
+
+// JiraAttachmentsAPI describes the operations used to attach test reports and to replace previous attachments.
+type JiraAttachmentsAPI interface {
+	JiraAPI
+	GetCurrentUser(ctx context.Context) (JiraUser, error)
+	AddAttachment(ctx context.Context, id string, attachment Attachment) error
+	DeleteAttachment(ctx context.Context, attachmentID string) error
+}
+
+// JiraTransitionsAPI describes the operations used to apply workflow transitions.
+type JiraTransitionsAPI interface {
+	JiraAPI
+	GetTransitions(ctx context.Context, id string) ([]JiraTransition, error)
+	TransitionIssue(ctx context.Context, id string, transitionID string) error
+}
+
+// JiraSearchAPI describes the operations used to search for issues, and to file and link new ones.
+// Without them, Sub-tasks are found by their summary only, and destinations cannot be discovered.
+type JiraSearchAPI interface {
+	JiraAPI
+	SearchIssues(ctx context.Context, jql string) ([]JiraIssue, error)
+	CreateIssue(ctx context.Context, project string, issueType string, summary string, description string, labels []string) (string, error)
+	LinkIssues(ctx context.Context, linkType string, inward string, outward string) error
+}
+
+// jiraCapability returns the client as the interface of an optional capability. If the client does not
+// implement it, an error naming the feature requiring the capability is returned.
+func jiraCapability[T JiraAPI](client JiraAPI, feature string) (T, error) {
+	capability, ok := client.(T)
+	if !ok {
+		return capability, fmt.Errorf("%s require a Jira client implementing %s", feature, reflect.TypeFor[T]().Name())
+	}
+
+	return capability, nil
+}
+
 // JiraClient manages communication with the Jira REST API.
 type JiraClient struct {
-	ServerURL   string
+	ServerURL string
+	// Auth adds credentials to every request, see NewJiraAuthenticator for the supported authentication schemes
+	Auth JiraAuthenticator
+	// AccessToken is a Personal Access Token used to authenticate requests if Auth is not set.
+	//
+	// Deprecated: Set Auth to a BearerTokenAuth instead.
 	AccessToken string
+	// Flavor selects the REST API version and description format
+	Flavor JiraFlavor
+	// HTTPClient is used to send requests. If nil, a client with the given Timeout is used.
+	// Set a custom client to change the transport, e.g. to route requests through a proxy.
+	// A custom client cannot be combined with client certificate authentication, which is part of the transport.
+	HTTPClient *http.Client
+	// Timeout limits the duration of a single request, including reading the response body.
+	// It is ignored if a custom HTTPClient is set
+	Timeout time.Duration
+	// Retry defines how requests failed due to rate limiting or temporary errors are retried
+	Retry JiraServerRetryConfig
+
+	// transport is used by the default HTTP client. It applies the TLS and proxy settings of the server config
+	transport   http.RoundTripper
+	rateLimiter *rateLimiter
+}
+
+// NewJiraClient creates a client for the Jira server described by a given config,
+// resolving the credentials of the configured authentication scheme and loading the TLS and proxy settings.
+// Copies of the client share the state of the rate limit reported by Jira.
+func NewJiraClient(config JiraServerConfig) (JiraClient, error) {
+	auth, err := NewJiraAuthenticator(config)
+	if err != nil {
+		return JiraClient{}, fmt.Errorf("credentials for Jira could not be loaded: %w", err)
+	}
+
+	transport, err := newJiraTransport(config)
+	if err != nil {
+		return JiraClient{}, fmt.Errorf("connection settings for Jira could not be loaded: %w", err)
+	}
+
+	return JiraClient{
+		ServerURL:   config.URL,
+		Auth:        auth,
+		Flavor:      config.Flavor,
+		Timeout:     config.Timeout,
+		Retry:       config.Retry,
+		transport:   transport,
+		rateLimiter: &rateLimiter{},
+	}, nil
 }
 
 // JiraIssue represents an Issue as returned by the Jira REST API.
@@ -29,8 +156,10 @@ type JiraIssue struct {
 	Parent      *JiraIssue
 	Summary     string
 	Description string
+	Status      string
 	Labels      []string
 	SubTasks    []*JiraIssue
+	Attachments []JiraAttachment
 }
 
 // IsLabeledWithAnyOf provides a convenience method to check whether the Jira Issue
@@ -48,6 +177,7 @@ func (issue *JiraIssue) IsLabeledWithAnyOf(ls []string) bool {
 type httpResponse struct {
 	Body       []byte
 	StatusCode int
+	Header     http.Header
 }
 
 type apiResponseIssue struct {
@@ -60,13 +190,19 @@ type apiResponseIssueType struct {
 	IsSubTask bool   `json:"subtask"`
 }
 
+type apiResponseIssueStatus struct {
+	Name string `json:"name"`
+}
+
 type apiResponseIssueFields struct {
-	IssueType   apiResponseIssueType `json:"issuetype"`
-	Parent      *apiResponseIssue    `json:"parent"`
-	Labels      []string             `json:"labels"`
-	Summary     string               `json:"summary"`
-	Description string               `json:"description"`
-	SubTasks    []*apiResponseIssue  `json:"subtasks"`
+	IssueType   apiResponseIssueType    `json:"issuetype"`
+	Status      apiResponseIssueStatus  `json:"status"`
+	Parent      *apiResponseIssue       `json:"parent"`
+	Labels      []string                `json:"labels"`
+	Summary     string                  `json:"summary"`
+	Description apiIssueDescription     `json:"description"`
+	SubTasks    []*apiResponseIssue     `json:"subtasks"`
+	Attachments []apiResponseAttachment `json:"attachment"`
 }
 
 // JiraIssue converts an Issue returned by the Jira REST API to the internal representation.
@@ -103,36 +239,168 @@ func (b *apiResponseIssue) JiraIssue() (JiraIssue, error) {
 		subtasks = append(subtasks, &subtask)
 	}
 
+	var attachments []JiraAttachment
+	for _, attachment := range b.Fields.Attachments {
+		attachments = append(attachments, JiraAttachment{
+			ID:       attachment.ID,
+			Filename: attachment.Filename,
+			AuthorID: attachment.Author.JiraUser().ID,
+		})
+	}
+
 	issue := JiraIssue{
 		ID:          b.Key,
 		Type:        b.Fields.IssueType.Name,
 		Parent:      &parent,
 		Summary:     b.Fields.Summary,
-		Description: b.Fields.Description,
+		Description: string(b.Fields.Description),
+		Status:      b.Fields.Status.Name,
 		Labels:      b.Fields.Labels,
 		SubTasks:    subtasks,
+		Attachments: attachments,
 	}
 
 	return issue, nil
 }
 
-func (c JiraClient) prepareRequest(method string, url string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body)
+func (c JiraClient) prepareRequest(ctx context.Context, method string, url string, body io.Reader, header http.Header) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header = http.Header{
-		"Authorization": {fmt.Sprintf("Bearer %s", c.AccessToken)},
-		"Content-Type":  {"application/json"},
+		"Content-Type": {"application/json"},
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	auth := c.Auth
+	if auth == nil && c.AccessToken != "" {
+		auth = BearerTokenAuth{Token: c.AccessToken}
+	}
+
+	if auth == nil {
+		return nil, errors.New("request could not be authenticated: no authentication scheme set")
+	}
+
+	if err := auth.Authenticate(req); err != nil {
+		return nil, fmt.Errorf("request could not be authenticated: %w", err)
 	}
 
 	return req, nil
 }
 
-func (c JiraClient) sendRequest(method string, url string, body io.Reader) (resp httpResponse, err error) {
-	client := http.Client{}
-	httpReq, err := c.prepareRequest(method, url, body)
+func (c JiraClient) issuesEndpoint() string {
+	if c.Flavor == JiraFlavorCloud {
+		return jiraCloudIssuesEndpoint
+	}
+
+	return jiraIssuesEndpoint
+}
+
+// descriptionValue converts a description to the value expected by the REST API. Jira Cloud expects
+// an ADF document, so descriptions that are not ADF documents yet are converted from the wiki markup.
+func (c JiraClient) descriptionValue(description string) (any, error) {
+	if c.Flavor != JiraFlavorCloud {
+		return description, nil
+	}
+
+	if isADFDocument(description) {
+		return json.RawMessage(description), nil
+	}
+
+	doc, err := renderADF(description)
+	if err != nil {
+		return nil, fmt.Errorf("description could not be converted to ADF: %w", err)
+	}
+
+	return json.RawMessage(doc), nil
+}
+
+// apiIssueDescription holds the description of an Issue. Descriptions returned as ADF documents
+// by Jira Cloud are kept as JSON-encoded strings.
+type apiIssueDescription string
+
+func (d *apiIssueDescription) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != nil {
+			*d = apiIssueDescription(*s)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return err
+	}
+	*d = apiIssueDescription(buf.String())
+
+	return nil
+}
+
+// sendRequest sends a request to the Jira REST API. Requests that fail due to rate limiting or temporary errors
+// are retried according to the retry config of the client (see isRetryableRequest), waiting either for the delay
+// requested by Jira or for an exponentially growing backoff between the attempts.
+func (c JiraClient) sendRequest(ctx context.Context, method string, url string, body []byte) (resp httpResponse, err error) {
+	return c.sendRequestWithHeader(ctx, method, url, body, nil)
+}
+
+// sendRequestWithHeader works like sendRequest, adding a given header to the request. Values set in the header
+// replace the default values, e.g. to send a body that is not JSON-encoded.
+func (c JiraClient) sendRequestWithHeader(ctx context.Context, method string, url string, body []byte, header http.Header) (resp httpResponse, err error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: c.Timeout, Transport: c.transport}
+	} else if _, ok := c.Auth.(tlsAuthenticator); ok {
+		return resp, errors.New("client certificate authentication cannot be used with a custom HTTP client")
+	}
+	maxAttempts := max(c.Retry.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		if err := c.rateLimiter.wait(ctx, c.Retry.MaxBackoff); err != nil {
+			return resp, err
+		}
+
+		resp, err = c.sendSingleRequest(ctx, client, method, url, body, header)
+		if err == nil {
+			c.rateLimiter.update(resp.Header)
+		}
+
+		// Requests are never retried once the context is done
+		if attempt >= maxAttempts || ctx.Err() != nil || !isRetryableRequest(method, resp.StatusCode, err) {
+			return resp, err
+		}
+
+		reason := fmt.Sprintf("HTTP status code: %d", resp.StatusCode)
+		if err != nil {
+			reason = err.Error()
+		}
+
+		delay, ok := rateLimitDelay(resp.Header, time.Now())
+		if !ok {
+			delay = c.Retry.backoff(attempt)
+		} else if c.Retry.MaxBackoff > 0 && delay > c.Retry.MaxBackoff {
+			warnLog(ctx).Printf("%s request to '%s' failed (%s). Jira asked to wait %s, which exceeds the max backoff. Giving up", method, url, reason, delay)
+			return resp, err
+		}
+
+		warnLog(ctx).Printf("%s request to '%s' failed (%s). Retrying in %s (attempt %d/%d)", method, url, reason, delay.Round(time.Millisecond), attempt+1, maxAttempts)
+		if err := sleep(ctx, delay); err != nil {
+			return resp, err
+		}
+	}
+}
+
+func (c JiraClient) sendSingleRequest(ctx context.Context, client *http.Client, method string, url string, body []byte, header http.Header) (resp httpResponse, err error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	httpReq, err := c.prepareRequest(ctx, method, url, bodyReader, header)
 	if err != nil {
 		return resp, err
 	}
@@ -150,20 +418,21 @@ func (c JiraClient) sendRequest(method string, url string, body io.Reader) (resp
 
 	resp.Body = b
 	resp.StatusCode = httpResp.StatusCode
+	resp.Header = httpResp.Header
 
 	return resp, nil
 }
 
 // GetIssue sends a request to Jira REST API to fetch an Issue with the given ID.
-func (c JiraClient) GetIssue(id string) (issue JiraIssue, err error) {
-	InfoLog.Printf("Getting Jira issue '%s'", id)
+func (c JiraClient) GetIssue(ctx context.Context, id string) (issue JiraIssue, err error) {
+	infoLog(ctx).Printf("Getting Jira issue '%s'", id)
 
-	endpointURL, err := url.JoinPath(c.ServerURL, jiraIssuesEndpoint, id)
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id)
 	if err != nil {
 		return issue, err
 	}
 
-	resp, err := c.sendRequest("GET", endpointURL, nil)
+	resp, err := c.sendRequest(ctx, "GET", endpointURL, nil)
 	if err != nil {
 		return issue, err
 	}
@@ -182,7 +451,7 @@ func (c JiraClient) GetIssue(id string) (issue JiraIssue, err error) {
 
 type apiRequestIssueUpdateFields struct {
 	Summary     string   `json:"summary"`
-	Description string   `json:"description"`
+	Description any      `json:"description"`
 	Labels      []string `json:"labels"`
 }
 
@@ -194,7 +463,7 @@ func (f apiRequestIssueUpdateFields) WrapAndMarshalJSON() ([]byte, error) {
 			"summary": []map[string]string{
 				{"set": f.Summary},
 			},
-			"description": []map[string]string{
+			"description": []map[string]any{
 				{"set": f.Description},
 			},
 			"labels": []map[string][]string{
@@ -211,17 +480,22 @@ func (f apiRequestIssueUpdateFields) WrapAndMarshalJSON() ([]byte, error) {
 }
 
 // UpdateIssue sends a request to Jira REST API to update an Issue with the given ID.
-func (c JiraClient) UpdateIssue(id string, summary string, description string, labels []string) error {
-	InfoLog.Printf("Updating Jira issue '%s' (%s, %v)", id, summary, labels)
+func (c JiraClient) UpdateIssue(ctx context.Context, id string, summary string, description string, labels []string) error {
+	infoLog(ctx).Printf("Updating Jira issue '%s' (%s, %v)", id, summary, labels)
 
-	endpointURL, err := url.JoinPath(c.ServerURL, jiraIssuesEndpoint, id)
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id)
 	if err != nil {
 		return err
 	}
 
+	descriptionValue, err := c.descriptionValue(description)
+	if err != nil {
+		return fmt.Errorf("issue %s could not be updated. Reason: %w", id, err)
+	}
+
 	fields := apiRequestIssueUpdateFields{
 		Summary:     summary,
-		Description: description,
+		Description: descriptionValue,
 		Labels:      labels,
 	}
 
@@ -230,7 +504,7 @@ func (c JiraClient) UpdateIssue(id string, summary string, description string, l
 		return err
 	}
 
-	resp, err := c.sendRequest("PUT", endpointURL, bytes.NewBuffer(payload))
+	resp, err := c.sendRequest(ctx, "PUT", endpointURL, payload)
 	if err != nil {
 		return fmt.Errorf("issue %s could not be updated. Reason: %w", id, err)
 	}
@@ -245,9 +519,9 @@ func (c JiraClient) UpdateIssue(id string, summary string, description string, l
 
 type apiRequestIssueCreateFields struct {
 	Project     map[string]string `json:"project"`
-	Parent      map[string]string `json:"parent"`
+	Parent      map[string]string `json:"parent,omitempty"`
 	Summary     string            `json:"summary"`
-	Description string            `json:"description"`
+	Description any               `json:"description"`
 	Labels      []string          `json:"labels"`
 	IssueType   map[string]string `json:"issuetype"`
 }
@@ -278,22 +552,43 @@ func getProjectFromParentIssueID(id string) string {
 }
 
 // CreateSubtask sends a request to create a Sub-task under a given parent Issue.
-func (c JiraClient) CreateSubtask(parent string, summary string, description string, labels []string) (string, error) {
-	InfoLog.Printf("Creating a new Jira issue under '%s'", parent)
+func (c JiraClient) CreateSubtask(ctx context.Context, parent string, summary string, description string, labels []string) (string, error) {
+	infoLog(ctx).Printf("Creating a new Jira issue under '%s'", parent)
+
+	fields := apiRequestIssueCreateFields{
+		Project:   map[string]string{"key": getProjectFromParentIssueID(parent)},
+		Parent:    map[string]string{"key": parent},
+		Summary:   summary,
+		Labels:    labels,
+		IssueType: map[string]string{"name": "Sub-task"},
+	}
+
+	return c.createIssue(ctx, "sub-task", fields, description)
+}
+
+// CreateIssue sends a request to create an Issue of a given type in a given project.
+func (c JiraClient) CreateIssue(ctx context.Context, project string, issueType string, summary string, description string, labels []string) (string, error) {
+	infoLog(ctx).Printf("Creating a new Jira %s in project '%s'", issueType, project)
+
+	fields := apiRequestIssueCreateFields{
+		Project:   map[string]string{"key": project},
+		Summary:   summary,
+		Labels:    labels,
+		IssueType: map[string]string{"name": issueType},
+	}
+
+	return c.createIssue(ctx, strings.ToLower(issueType), fields, description)
+}
 
-	endpointURL, err := url.JoinPath(c.ServerURL, jiraIssuesEndpoint)
+func (c JiraClient) createIssue(ctx context.Context, kind string, fields apiRequestIssueCreateFields, description string) (string, error) {
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint())
 	if err != nil {
 		return "", err
 	}
 
-	project := getProjectFromParentIssueID(parent)
-	fields := apiRequestIssueCreateFields{
-		Project:     map[string]string{"key": project},
-		Parent:      map[string]string{"key": parent},
-		Summary:     summary,
-		Description: description,
-		Labels:      labels,
-		IssueType:   map[string]string{"name": "Sub-task"},
+	fields.Description, err = c.descriptionValue(description)
+	if err != nil {
+		return "", fmt.Errorf("%s could not be created. Reason: %w", kind, err)
 	}
 
 	payload, err := fields.WrapAndMarshalJSON()
@@ -301,14 +596,14 @@ func (c JiraClient) CreateSubtask(parent string, summary string, description str
 		return "", err
 	}
 
-	resp, err := c.sendRequest("POST", endpointURL, bytes.NewBuffer(payload))
+	resp, err := c.sendRequest(ctx, "POST", endpointURL, payload)
 	if err != nil {
-		return "", fmt.Errorf("sub-task could not be created. Reason: %w", err)
+		return "", fmt.Errorf("%s could not be created. Reason: %w", kind, err)
 	}
 
 	// Jira REST API returns Status Code 201 if issue was created
 	if resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("sub-task could not be created. HTTP status code: %d", resp.StatusCode)
+		return "", fmt.Errorf("%s could not be created. HTTP status code: %d", kind, resp.StatusCode)
 	}
 
 	var data apiResponseIssueCreate
@@ -318,3 +613,39 @@ func (c JiraClient) CreateSubtask(parent string, summary string, description str
 
 	return data.Key, nil
 }
+
+// LinkIssues sends a request to link two Issues with a link of the given type (e.g. "Relates").
+func (c JiraClient) LinkIssues(ctx context.Context, linkType string, inward string, outward string) error {
+	infoLog(ctx).Printf("Linking Jira issues '%s' and '%s' (%s)", inward, outward, linkType)
+
+	endpoint := jiraIssueLinkEndpoint
+	if c.Flavor == JiraFlavorCloud {
+		endpoint = jiraCloudIssueLinkEndpoint
+	}
+
+	endpointURL, err := url.JoinPath(c.ServerURL, endpoint)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"type":         map[string]string{"name": linkType},
+		"inwardIssue":  map[string]string{"key": inward},
+		"outwardIssue": map[string]string{"key": outward},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.sendRequest(ctx, "POST", endpointURL, payload)
+	if err != nil {
+		return fmt.Errorf("issues %s and %s could not be linked. Reason: %w", inward, outward, err)
+	}
+
+	// Jira REST API returns Status Code 201 if the link was created
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("issues %s and %s could not be linked. HTTP status code: %d", inward, outward, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -1,10 +1,13 @@
 package reporter
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/joshdk/go-junit"
 	"golang.org/x/exp/maps"
@@ -14,21 +17,50 @@ import (
 const MatchAllSymbol = "*"
 
 // Counts provides a container for storing information about test counts.
+// Flaky Test Cases are also counted according to their final status, so they are not included in the Total twice.
 type Counts struct {
-	Passed  int
-	Failed  int
-	Errored int
-	Skipped int
-	Total   int
+	Passed  int `json:"passed" yaml:"passed"`
+	Failed  int `json:"failed" yaml:"failed"`
+	Errored int `json:"errored" yaml:"errored"`
+	Skipped int `json:"skipped" yaml:"skipped"`
+	Flaky   int `json:"flaky" yaml:"flaky"`
+	Total   int `json:"total" yaml:"total"`
+}
+
+// Executed returns the number of Test Cases that have not been skipped.
+func (c Counts) Executed() int {
+	return c.Total - c.Skipped
+}
+
+// PassRate returns the percentage of passed Test Cases out of the executed ones, or 0 if none have been executed.
+func (c Counts) PassRate() float64 {
+	if c.Executed() == 0 {
+		return 0
+	}
+
+	return float64(c.Passed) * 100 / float64(c.Executed())
 }
 
 // Add increases test counts based on the status of the Test Case given by the user.
 func (c *Counts) Add(test junit.Test) {
-	if test.Status == junit.StatusPassed {
+	c.addStatus(test.Status)
+}
+
+// AddTestCase increases test counts based on the status of a processed Test Case.
+func (c *Counts) AddTestCase(test TestCase) {
+	c.addStatus(test.Status)
+
+	if test.Flaky {
+		c.Flaky++
+	}
+}
+
+func (c *Counts) addStatus(status junit.Status) {
+	if status == junit.StatusPassed {
 		c.Passed++
-	} else if test.Status == junit.StatusSkipped {
+	} else if status == junit.StatusSkipped {
 		c.Skipped++
-	} else if test.Status == junit.StatusError {
+	} else if status == junit.StatusError {
 		c.Errored++
 	} else {
 		c.Failed++
@@ -37,10 +69,87 @@ func (c *Counts) Add(test junit.Test) {
 	c.Total++
 }
 
+// maxTestCaseOutputLength limits the length of stack traces and outputs stored for each Test Case.
+const maxTestCaseOutputLength = 2000
+
+// TestCase represents a single Test Case from a test report.
+type TestCase struct {
+	Name       string
+	Classname  string
+	Duration   time.Duration
+	Status     junit.Status
+	Message    string
+	StackTrace string
+	SkipReason string
+	SystemOut  string
+	SystemErr  string
+	Properties map[string]string
+
+	// Attempts is the number of times the Test Case was found in the test reports
+	// and Flaky marks Test Cases that both failed and passed across these attempts.
+	// Both are set only if deduplication of Test Cases is enabled.
+	Attempts int
+	Flaky    bool
+
+	// Index of the Test Case within the loaded Test Suite
+	index int
+}
+
+// NewTestCase converts a Test Case loaded from a test report to the internal representation.
+// Stack traces and outputs longer than maxTestCaseOutputLength characters are truncated.
+func NewTestCase(test junit.Test) TestCase {
+	tc := TestCase{
+		Name:       test.Name,
+		Classname:  test.Classname,
+		Duration:   test.Duration,
+		Status:     test.Status,
+		SystemOut:  truncateString(test.SystemOut, maxTestCaseOutputLength),
+		SystemErr:  truncateString(test.SystemErr, maxTestCaseOutputLength),
+		Properties: test.Properties,
+	}
+
+	if test.Status == junit.StatusSkipped {
+		tc.SkipReason = test.Message
+	} else {
+		tc.Message = test.Message
+	}
+
+	var junitErr junit.Error
+	if errors.As(test.Error, &junitErr) {
+		if tc.Message == "" {
+			tc.Message = junitErr.Message
+		}
+		tc.StackTrace = truncateString(strings.TrimSpace(junitErr.Body), maxTestCaseOutputLength)
+	}
+
+	return tc
+}
+
+// IsFailed checks whether the Test Case has either failed or errored.
+func (tc TestCase) IsFailed() bool {
+	return tc.Status == junit.StatusFailed || tc.Status == junit.StatusError
+}
+
+func truncateString(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+
+	return string(runes[:maxLength]) + "... (truncated)"
+}
+
 // TestSuite represents a Test Suite from a test report.
 type TestSuite struct {
-	Name   string
-	Counts Counts
+	Name      string
+	Counts    Counts
+	TestCases []TestCase
+}
+
+// FailedTestCase represents a failed or errored Test Case along with the name of its Test Suite.
+type FailedTestCase struct {
+	TestSuite string
+	TestCase
 }
 
 // AggregateReport stores information about all Test Suites and Test Cases
@@ -49,6 +158,20 @@ type AggregateReport struct {
 	Destination string
 	TestSuites  []TestSuite
 	Counts      Counts
+	// Attachments are uploaded to the Jira issue if attachments are enabled in the desired state config
+	Attachments []Attachment
+	// DesiredState is merged over the global desired state when the report is uploaded, if set by its route
+	DesiredState JiraIssueDesiredStateOverrideConfig
+
+	// Paths of the test reports the Test Suites of the report have been loaded from
+	sources []string
+}
+
+// Sources returns the paths of the test reports given as input, from which any Test Suites have been added
+// to the report by ProcessJUnitReports, in the order they were loaded. Test reports found in an archive share
+// the path of the archive.
+func (r AggregateReport) Sources() []string {
+	return r.sources
 }
 
 // AggregateCounts takes all Test Suites contained in the report and calculates the total sum on all Counters.
@@ -60,10 +183,24 @@ func (r *AggregateReport) AggregateCounts() {
 		r.Counts.Failed += suite.Counts.Failed
 		r.Counts.Errored += suite.Counts.Errored
 		r.Counts.Skipped += suite.Counts.Skipped
+		r.Counts.Flaky += suite.Counts.Flaky
 		r.Counts.Total += suite.Counts.Total
 	}
 }
 
+// FailedTestCases returns all failed and errored Test Cases contained in the report.
+func (r AggregateReport) FailedTestCases() (tests []FailedTestCase) {
+	for _, suite := range r.TestSuites {
+		for _, test := range suite.TestCases {
+			if test.IsFailed() {
+				tests = append(tests, FailedTestCase{TestSuite: suite.Name, TestCase: test})
+			}
+		}
+	}
+
+	return tests
+}
+
 // LogAggregateReports dumps the AggregateReports in a human-readable form to a given logger.
 func LogAggregateReports(logger *log.Logger, reports []AggregateReport) {
 	logger.Println("Printing Aggregate Reports created based on configured routing rules")
@@ -80,15 +217,44 @@ func LogAggregateReports(logger *log.Logger, reports []AggregateReport) {
 			note = "(no data to upload)"
 		}
 
-		logger.Printf("%-3s Passed %-4d Failed %-4d Errored %-4d Skipped %-4d Total %-4d -> Jira %s %s",
-			fmt.Sprintf("%d)", i+1), c.Passed, c.Failed, c.Errored, c.Skipped, c.Total, dest, note)
+		logger.Printf("%-3s Passed %-4d Failed %-4d Errored %-4d Skipped %-4d Flaky %-4d Total %-4d -> Jira %s %s",
+			fmt.Sprintf("%d)", i+1), c.Passed, c.Failed, c.Errored, c.Skipped, c.Flaky, c.Total, dest, note)
 	}
 }
 
 func groupRouteConfigsByDestination(routeConfigs []ReportingRouteConfig) []ReportingRouteConfig {
-	configs := map[string][]ReportingTestSuiteConfig{}
+	configs := map[string]*ReportingRouteConfig{}
 	for _, config := range routeConfigs {
-		configs[config.Destination] = append(configs[config.Destination], config.TestSuites...)
+		grouped, ok := configs[config.Destination]
+		if !ok {
+			grouped = &ReportingRouteConfig{
+				Destination:         config.Destination,
+				TestSuites:          []ReportingTestSuiteConfig{},
+				destinationTemplate: config.destinationTemplate,
+				discovered:          config.discovered,
+			}
+			configs[config.Destination] = grouped
+		}
+
+		// Test Suites of a destination also routed statically are always added to its report
+		grouped.discovered = grouped.discovered && config.discovered
+
+		// Compile ensures that routes sharing the same destination do not define different desired states
+		if grouped.DesiredState == nil {
+			grouped.DesiredState = config.DesiredState
+		}
+
+		suites := config.TestSuites
+		if suites == nil {
+			suites = matchAllTestSuiteRules
+		}
+
+		// Route-level exclusions are attached to each Test Suite rule, so that they
+		// do not affect the rules of other routes sharing the same destination
+		for _, suite := range suites {
+			suite.routeExclude = slices.Concat(suite.routeExclude, config.Exclude)
+			grouped.TestSuites = append(grouped.TestSuites, suite)
+		}
 	}
 
 	destinations := maps.Keys(configs)
@@ -96,110 +262,271 @@ func groupRouteConfigsByDestination(routeConfigs []ReportingRouteConfig) []Repor
 
 	groupedRouteConfigs := []ReportingRouteConfig{}
 	for _, dest := range destinations {
-		suites := configs[dest]
-		groupedRouteConfigs = append(groupedRouteConfigs, ReportingRouteConfig{
-			Destination: dest,
-			TestSuites:  suites,
-		})
+		groupedRouteConfigs = append(groupedRouteConfigs, *configs[dest])
 	}
 
 	return groupedRouteConfigs
 }
 
-// ProcessJUnitReports loads and analyzes JUnit Test Reports according to the routing config defined by the user.
-func ProcessJUnitReports(paths []string, config ReportingConfig) (reports []AggregateReport, err error) {
-	suites, err := junit.IngestFiles(paths)
-	if err != nil {
-		return nil, err
-	}
+// reportSet collects the results of one or more routes and groups them into AggregateReports by destination.
+// Test Suites are added one at a time, so that they do not need to be kept in memory once they are processed.
+type reportSet struct {
+	entries  map[string]*reportSetEntry
+	matchers []*routeMatcher
+	suites   *suiteRuleIndex
+	// Number of Test Cases whose dynamic destination could not be resolved, for each route
+	unresolved []int
+	// Number of Test Suites added so far, used as the index of the next Test Suite
+	suiteCount int
+}
 
-	routing := config.Routing
-	if routing == nil {
-		routing = []ReportingRouteConfig{{}}
+type reportSetEntry struct {
+	// Processed Test Suites, keyed by the index of the loaded Test Suite
+	suites map[int]*TestSuite
+	// Test Cases already counted in the report, identified by the Test Suite and Test Case indices
+	counted map[[2]int]bool
+	// Desired state of the first route adding Test Cases to the report that defines one
+	desiredState JiraIssueDesiredStateOverrideConfig
+	// Paths of the test reports the processed Test Suites have been loaded from, in the order they were loaded
+	sources []string
+}
+
+func newReportSet(routes []ReportingRouteConfig) *reportSet {
+	s := &reportSet{
+		entries:    map[string]*reportSetEntry{},
+		unresolved: make([]int, len(routes)),
 	}
 
-	routes := groupRouteConfigsByDestination(routing)
 	for _, route := range routes {
-		report := ProcessJUnitSuites(suites, route)
-		reports = append(reports, report)
+		s.matchers = append(s.matchers, newRouteMatcher(route))
+
+		// Static routes always produce a report, even if no Test Cases have been selected
+		if route.destinationTemplate == nil {
+			s.entry(route.Destination, route.DesiredState)
+		}
 	}
+	s.suites = newSuiteRuleIndex(s.matchers)
 
-	return reports, nil
+	return s
+}
+
+func (s *reportSet) entry(destination string, desiredState JiraIssueDesiredStateOverrideConfig) *reportSetEntry {
+	e, ok := s.entries[destination]
+	if !ok {
+		e = &reportSetEntry{
+			suites:  map[int]*TestSuite{},
+			counted: map[[2]int]bool{},
+		}
+		s.entries[destination] = e
+	}
+
+	if e.desiredState == nil {
+		e.desiredState = desiredState
+	}
+
+	return e
 }
 
-func isEntityMatchedByNameRule(entityName string, nameRule string) bool {
-	if nameRule == "" {
-		return false
+func (e *reportSetEntry) testSuite(index int, name string, source string) *TestSuite {
+	suite, ok := e.suites[index]
+	if !ok {
+		suite = &TestSuite{Name: name}
+		e.suites[index] = suite
+
+		if source != "" && !slices.Contains(e.sources, source) {
+			e.sources = append(e.sources, source)
+		}
 	}
 
-	return nameRule == MatchAllSymbol || nameRule == entityName
+	return suite
 }
 
-func isEntityMatchedByPropertyRule(entityProperties map[string]string, propertyRule string) bool {
-	if propertyRule == "" {
-		return false
+// add processes a loaded Test Suite according to all routes of the set and adds the selected Test Cases
+// to the report of their destination. Each Test Case is counted only once per destination, even if
+// it is selected by multiple rules or routes. The source is the path of the test report the Test Suite
+// has been loaded from, if known.
+func (s *reportSet) add(suite junit.Suite, source string) error {
+	suiteIndex := s.suiteCount
+	s.suiteCount++
+
+	// Only routes with a Test Suite rule matching the Test Suite are evaluated
+	routes, rules := s.suites.matching(suite)
+	for k, i := range routes {
+		m := s.matchers[i]
+		selectors := m.selectTestCases(suite, rules[k])
+		if len(selectors) == 0 {
+			continue
+		}
+
+		route := &m.route
+		isDynamic := route.destinationTemplate != nil
+		// Discovered routes match all Test Suites by the properties of their Test Cases, so their Test Suites
+		// are added to the report only once any of their Test Cases is selected. Test Suites without any
+		// Test Cases are reported as they are
+		if !isDynamic && (!route.discovered || len(suite.Tests) == 0) {
+			s.entry(route.Destination, route.DesiredState).testSuite(suiteIndex, suite.Name, source)
+		}
+
+		for testIndex, test := range suite.Tests {
+			if !slices.ContainsFunc(selectors, func(s testCaseSelector) bool { return s.selects(test) }) {
+				continue
+			}
+
+			destination := route.Destination
+			if isDynamic {
+				var err error
+				destination, err = route.resolveDestination(suite, test)
+				if err != nil {
+					return err
+				}
+
+				if destination == "" {
+					s.unresolved[i]++
+					continue
+				}
+			}
+
+			e := s.entry(destination, route.DesiredState)
+			if e.counted[[2]int{suiteIndex, testIndex}] {
+				continue
+			}
+			e.counted[[2]int{suiteIndex, testIndex}] = true
+
+			processedTestSuite := e.testSuite(suiteIndex, suite.Name, source)
+			processedTestSuite.Counts.Add(test)
+
+			processedTestCase := NewTestCase(test)
+			processedTestCase.index = testIndex
+			processedTestSuite.TestCases = append(processedTestSuite.TestCases, processedTestCase)
+		}
 	}
 
-	separator := "="
-	p := strings.Split(propertyRule, separator)
-	if len(p) < 2 {
-		return false
+	return nil
+}
+
+// logUnresolvedDestinations warns about Test Cases left out of the reports because
+// the dynamic destination of their route could not be resolved.
+func (s *reportSet) logUnresolvedDestinations() {
+	for i, count := range s.unresolved {
+		if count > 0 {
+			WarnLog.Printf("Destination '%s' could not be resolved for %d Test Case(s). They will not be reported", s.matchers[i].route.Destination, count)
+		}
 	}
+}
 
-	name := p[0]
-	value := strings.Join(p[1:], separator)
+// AggregateReports returns all collected AggregateReports sorted by their destination.
+// Test Suites and Test Cases are kept in the same order as they were loaded, regardless of the order of routes.
+func (s *reportSet) AggregateReports() []AggregateReport {
+	destinations := maps.Keys(s.entries)
+	sort.Strings(destinations)
 
-	return entityProperties[name] == value
+	reports := []AggregateReport{}
+	for _, dest := range destinations {
+		e := s.entries[dest]
+		report := AggregateReport{Destination: dest, DesiredState: e.desiredState, sources: e.sources}
+
+		indices := maps.Keys(e.suites)
+		sort.Ints(indices)
+		for _, i := range indices {
+			suite := *e.suites[i]
+			sort.SliceStable(suite.TestCases, func(a, b int) bool { return suite.TestCases[a].index < suite.TestCases[b].index })
+			report.TestSuites = append(report.TestSuites, suite)
+		}
+
+		report.AggregateCounts()
+		reports = append(reports, report)
+	}
+
+	return reports
 }
 
-// ProcessJUnitSuites processes all loaded Test Suites according to a given routing configuration.
-// A single AggregateReport will be created for each route defined by the user. If any Test Suites
-// or Test Cases match any of the rules defined for this route, they will be added to the Report.
-func ProcessJUnitSuites(suites []junit.Suite, route ReportingRouteConfig) (report AggregateReport) {
-	report.Destination = route.Destination
+// ProcessJUnitReports loads and analyzes test reports according to the routing config defined by the user.
+// Test reports in formats other than JUnit are converted to JUnit Test Suites first. Test reports are parsed
+// concurrently and each Test Suite is routed as soon as it is loaded, so that only the processed
+// Test Cases are kept in memory.
+func ProcessJUnitReports(paths []string, config ReportingConfig) (reports []AggregateReport, err error) {
+	if err := config.Compile(); err != nil {
+		return nil, err
+	}
 
-	for _, suite := range suites {
-		var testCaseRules []ReportingTestCaseConfig
+	routing := config.Routing
+	if routing == nil {
+		routing = []ReportingRouteConfig{{}}
+	}
 
-		processedTestSuite := TestSuite{
-			Name: suite.Name,
+	set := newReportSet(groupRouteConfigsByDestination(routing))
+	err = ingestFiles(paths, config.InputFormat, config.MaxConcurrency, func(path string, suites []junit.Suite) error {
+		for _, suite := range suites {
+			if err := set.add(suite, path); err != nil {
+				return err
+			}
 		}
-		testCaseRuleMatchAll := ReportingTestCaseConfig{
-			Name: MatchAllSymbol,
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	set.logUnresolvedDestinations()
+	reports = set.AggregateReports()
+
+	for i := range reports {
+		reports[i].Deduplicate(config.Deduplication.Policy)
+	}
+
+	return reports, nil
+}
+
+// ProcessJUnitRoutes processes all loaded Test Suites according to the given routes. Routes sharing the same
+// destination are grouped together, and routes with a dynamic destination are resolved for each selected
+// Test Case. A single AggregateReport is created for each resulting destination. Rules and destination templates
+// that have not been compiled with ReportingConfig.Compile are compiled first, and an error is returned if any
+// of them is invalid.
+func ProcessJUnitRoutes(suites []junit.Suite, routes []ReportingRouteConfig) ([]AggregateReport, error) {
+	routes = slices.Clone(routes)
+	for i := range routes {
+		if err := routes[i].compile(); err != nil {
+			return nil, fmt.Errorf("routing[%d]: %w", i, err)
 		}
 
-		if route.TestSuites != nil {
-			for _, rule := range route.TestSuites {
-				if isEntityMatchedByNameRule(suite.Name, rule.Name) {
-					if rule.TestCases != nil {
-						testCaseRules = append(testCaseRules, rule.TestCases...)
-					} else {
-						testCaseRules = append(testCaseRules, testCaseRuleMatchAll)
-					}
-				} else if isEntityMatchedByPropertyRule(suite.Properties, rule.Property) {
-					testCaseRules = append(testCaseRules, rule.TestCases...)
-				}
-			}
-		} else {
-			// Apply a Match-All rule when a route has no been configured
-			testCaseRules = append(testCaseRules, testCaseRuleMatchAll)
-		}
-
-		if len(testCaseRules) > 0 {
-			// This loop could be optimized, but it probably is not worth the extra effort
-			for _, test := range suite.Tests {
-				for _, rule := range testCaseRules {
-					if isEntityMatchedByNameRule(test.Name, rule.Name) || isEntityMatchedByPropertyRule(test.Properties, rule.Property) {
-						processedTestSuite.Counts.Add(test)
-					}
-				}
-			}
+		if err := routes[i].compileRules(fmt.Sprintf("routing[%d]", i)); err != nil {
+			return nil, err
+		}
+	}
 
-			report.TestSuites = append(report.TestSuites, processedTestSuite)
+	set := newReportSet(groupRouteConfigsByDestination(routes))
+	for _, suite := range suites {
+		if err := set.add(suite, ""); err != nil {
+			return nil, err
 		}
 	}
 
-	report.AggregateCounts()
+	set.logUnresolvedDestinations()
+
+	return set.AggregateReports(), nil
+}
+
+// ProcessJUnitSuites processes all loaded Test Suites according to a given routing configuration.
+// A single AggregateReport will be created for each route defined by the user. If any Test Suites
+// or Test Cases match any of the rules defined for this route, they will be added to the Report,
+// unless they are also matched by any of the exclusion rules. Test Suites matched by a rule without any
+// Test Case rules, whether by name or by property, contribute all of their Test Cases, and each Test Case
+// is counted once, even if it is matched by multiple rules.
+// Rules that have not been compiled with ReportingConfig.Compile are compiled first, and an error is returned
+// if any of them is invalid. The destination of the route is used as is, see ProcessJUnitRoutes for routes
+// with dynamic destinations.
+func ProcessJUnitSuites(suites []junit.Suite, route ReportingRouteConfig) (AggregateReport, error) {
+	if err := route.compileRules("route"); err != nil {
+		return AggregateReport{}, err
+	}
+
+	route.destinationTemplate = nil
+
+	set := newReportSet([]ReportingRouteConfig{route})
+	for _, suite := range suites {
+		// Static routes are always resolved and cannot fail
+		_ = set.add(suite, "")
+	}
 
-	return report
+	return set.AggregateReports()[0], nil
 }
@@ -0,0 +1,123 @@
+package reporter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+)
+
+func TestProcessJUnitReportsCapturesTestCases(t *testing.T) {
+	paths := []string{
+		"testdata/valid/simple_failure.xml",
+		"testdata/valid/simple_error.xml",
+		"testdata/valid/multiple_test_cases.xml",
+	}
+
+	reports, err := ProcessJUnitReports(paths, ReportingConfig{})
+	if err != nil {
+		t.Fatalf("ProcessJUnitReports failed: %s", err)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+
+	report := reports[0]
+	if len(report.TestSuites) != 3 {
+		t.Fatalf("expected 3 test suites, got %d", len(report.TestSuites))
+	}
+
+	names := []string{}
+	for _, test := range report.TestSuites[2].TestCases {
+		names = append(names, test.Name)
+	}
+	if strings.Join(names, ",") != "one,two,three,four,five" {
+		t.Fatalf("test cases are not in the loaded order: %v", names)
+	}
+
+	failed := report.FailedTestCases()
+	if len(failed) != 2 {
+		t.Fatalf("expected 2 failed test cases, got %d", len(failed))
+	}
+
+	if failed[0].TestSuite != "testdata.valid.simple" || failed[0].Status != junit.StatusFailed || failed[0].Message != "This is a failure message" {
+		t.Fatalf("unexpected failed test case: %+v", failed[0])
+	}
+
+	if failed[1].Status != junit.StatusError || failed[1].Message != "This is an error message" {
+		t.Fatalf("unexpected errored test case: %+v", failed[1])
+	}
+}
+
+func TestProcessJUnitReportsTracksSources(t *testing.T) {
+	paths := []string{
+		"testdata/valid/simple_failure.xml",
+		"testdata/valid/multiple_test_cases.xml",
+		"testdata/valid/simple_error.xml",
+	}
+
+	config := ReportingConfig{Routing: []ReportingRouteConfig{
+		{
+			Destination: "EXAMPLE-15",
+			TestSuites:  []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "testdata.valid.simple"}}},
+		},
+		{
+			Destination: "EXAMPLE-20",
+			TestSuites:  []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "testdata.valid.multiple_test_cases"}}},
+		},
+		{
+			Destination: "EXAMPLE-25",
+			TestSuites:  []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "missing"}}},
+		},
+	}}
+
+	reports, err := ProcessJUnitReports(paths, config)
+	if err != nil {
+		t.Fatalf("ProcessJUnitReports failed: %s", err)
+	}
+
+	expected := map[string][]string{
+		"EXAMPLE-15": {paths[0], paths[2]},
+		"EXAMPLE-20": {paths[1]},
+		"EXAMPLE-25": nil,
+	}
+	for _, report := range reports {
+		if !slices.Equal(report.Sources(), expected[report.Destination]) {
+			t.Fatalf("expected sources %v for %s, got %v", expected[report.Destination], report.Destination, report.Sources())
+		}
+	}
+}
+
+func TestNewTestCase(t *testing.T) {
+	test := junit.Test{
+		Name:    "skipped",
+		Status:  junit.StatusSkipped,
+		Message: "Not supported on this platform",
+	}
+
+	tc := NewTestCase(test)
+	if tc.SkipReason != test.Message || tc.Message != "" {
+		t.Fatalf("skip reason not captured correctly: %+v", tc)
+	}
+
+	test = junit.Test{
+		Name:   "failed",
+		Status: junit.StatusFailed,
+		Error: junit.Error{
+			Message: "Assertion failed",
+			Body:    strings.Repeat("x", maxTestCaseOutputLength+1),
+		},
+		SystemOut: "output",
+	}
+
+	tc = NewTestCase(test)
+	if tc.Message != "Assertion failed" || tc.SystemOut != "output" {
+		t.Fatalf("failure details not captured correctly: %+v", tc)
+	}
+
+	if !strings.HasSuffix(tc.StackTrace, "(truncated)") || len(tc.StackTrace) <= maxTestCaseOutputLength {
+		t.Fatalf("stack trace was not truncated: %d characters", len(tc.StackTrace))
+	}
+}
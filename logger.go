@@ -1,8 +1,12 @@
 package reporter
 
 import (
+	"context"
+	"io"
 	"log"
 	"os"
+	"slices"
+	"sync"
 )
 
 var (
@@ -12,3 +16,73 @@ var (
 	WarnLog  = log.New(os.Stdout, "[WARN] ", logFlags)
 	ErrorLog = log.New(os.Stderr, "[ERROR] ", logFlags)
 )
+
+type contextLoggersKey struct{}
+
+type contextLoggers struct {
+	info *log.Logger
+	warn *log.Logger
+}
+
+// logBuffer holds messages logged by multiple loggers in the order they were logged, until they are flushed
+// to the outputs of the loggers. It keeps the messages of a single task together when tasks run concurrently.
+type logBuffer struct {
+	mu      sync.Mutex
+	entries []logBufferEntry
+}
+
+type logBufferEntry struct {
+	w io.Writer
+	p []byte
+}
+
+// logBufferWriter records messages written to a buffer along with the output they are meant for.
+type logBufferWriter struct {
+	buf *logBuffer
+	w   io.Writer
+}
+
+func (w logBufferWriter) Write(p []byte) (int, error) {
+	w.buf.mu.Lock()
+	defer w.buf.mu.Unlock()
+
+	w.buf.entries = append(w.buf.entries, logBufferEntry{w.w, slices.Clone(p)})
+	return len(p), nil
+}
+
+// flush writes all buffered messages to the outputs of their loggers.
+func (b *logBuffer) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range b.entries {
+		e.w.Write(e.p)
+	}
+	b.entries = nil
+}
+
+// withLogBuffer returns a context, in which messages logged with infoLog and warnLog are kept in a given buffer.
+func withLogBuffer(ctx context.Context, buf *logBuffer) context.Context {
+	return context.WithValue(ctx, contextLoggersKey{}, contextLoggers{
+		info: log.New(logBufferWriter{buf, InfoLog.Writer()}, InfoLog.Prefix(), InfoLog.Flags()),
+		warn: log.New(logBufferWriter{buf, WarnLog.Writer()}, WarnLog.Prefix(), WarnLog.Flags()),
+	})
+}
+
+// infoLog returns the logger for informational messages related to a given context.
+func infoLog(ctx context.Context) *log.Logger {
+	if loggers, ok := ctx.Value(contextLoggersKey{}).(contextLoggers); ok {
+		return loggers.info
+	}
+
+	return InfoLog
+}
+
+// warnLog returns the logger for warnings related to a given context.
+func warnLog(ctx context.Context) *log.Logger {
+	if loggers, ok := ctx.Value(contextLoggersKey{}).(contextLoggers); ok {
+		return loggers.warn
+	}
+
+	return WarnLog
+}
@@ -0,0 +1,108 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SubtaskAction describes what happened to the Sub-task with test results during an upload.
+type SubtaskAction string
+
+const (
+	// SubtaskActionCreated marks Sub-tasks created during the upload.
+	SubtaskActionCreated SubtaskAction = "created"
+	// SubtaskActionUpdated marks existing Sub-tasks updated with the latest test results.
+	SubtaskActionUpdated SubtaskAction = "updated"
+	// SubtaskActionUnchanged marks existing Sub-tasks that were already up to date.
+	SubtaskActionUnchanged SubtaskAction = "unchanged"
+)
+
+// UploadResult describes the outcome of uploading a single AggregateReport to Jira.
+type UploadResult struct {
+	Destination string        `json:"destination" yaml:"destination"`
+	Subtask     string        `json:"subtask,omitempty" yaml:"subtask,omitempty"`
+	Action      SubtaskAction `json:"action,omitempty" yaml:"action,omitempty"`
+	Counts      Counts        `json:"counts" yaml:"counts"`
+	Labels      []string      `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Uploaded    bool          `json:"uploaded" yaml:"uploaded"`
+	Error       string        `json:"error,omitempty" yaml:"error,omitempty"`
+	// StartedAt and Duration (in seconds) are only set for reports an upload has been attempted for
+	StartedAt *time.Time `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	Duration  float64    `json:"durationSeconds,omitempty" yaml:"durationSeconds,omitempty"`
+}
+
+// NewUploadResult returns the result of a given report, which has not been uploaded (yet).
+func NewUploadResult(report AggregateReport) UploadResult {
+	return UploadResult{
+		Destination: report.Destination,
+		Counts:      report.Counts,
+	}
+}
+
+// OutputFormat represents a format in which the results of an upload can be written.
+type OutputFormat string
+
+const (
+	OutputFormatJSON OutputFormat = "json"
+	OutputFormatYAML OutputFormat = "yaml"
+)
+
+// OutputFormats lists all supported output formats.
+var OutputFormats = []OutputFormat{OutputFormatJSON, OutputFormatYAML}
+
+// Validate checks whether the format is one of the supported output formats.
+func (f OutputFormat) Validate() error {
+	if slices.Contains(OutputFormats, f) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown output format '%s'. Expected one of: %v", f, OutputFormats)
+}
+
+// uploadResultsDocument is the structure of the document the results of an upload are written as.
+type uploadResultsDocument struct {
+	Uploaded       int                    `json:"uploaded" yaml:"uploaded"`
+	Total          int                    `json:"total" yaml:"total"`
+	Reports        []UploadResult         `json:"reports" yaml:"reports"`
+	GateViolations []QualityGateViolation `json:"gateViolations" yaml:"gateViolations"`
+}
+
+// WriteUploadResults writes the results of an upload, along with the quality gates that have not been met,
+// in a given format, so that they can be processed by other tools.
+func WriteUploadResults(w io.Writer, format OutputFormat, results []UploadResult, violations []QualityGateViolation) error {
+	doc := uploadResultsDocument{Total: len(results), Reports: results, GateViolations: violations}
+	for _, result := range results {
+		if result.Uploaded {
+			doc.Uploaded++
+		}
+	}
+
+	if doc.Reports == nil {
+		doc.Reports = []UploadResult{}
+	}
+
+	if doc.GateViolations == nil {
+		doc.GateViolations = []QualityGateViolation{}
+	}
+
+	switch format {
+	case OutputFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case OutputFormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	return format.Validate()
+}
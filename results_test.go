@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteUploadResults(t *testing.T) {
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	results := []UploadResult{
+		{
+			Destination: "EXAMPLE-15",
+			Subtask:     "EXAMPLE-16",
+			Action:      SubtaskActionCreated,
+			Counts:      Counts{Passed: 1, Failed: 1, Total: 2},
+			Labels:      []string{"FAILED"},
+			Uploaded:    true,
+			StartedAt:   &startedAt,
+			Duration:    1.5,
+		},
+		{
+			Destination: "EXAMPLE-404",
+			Counts:      Counts{Passed: 1, Total: 1},
+			Error:       "issue EXAMPLE-404 could not be fetched. HTTP status code: 404",
+		},
+	}
+
+	violations := []QualityGateViolation{{Scope: "EXAMPLE-15", Gate: "maxFailed", Message: "1 test cases failed, at most 0 allowed"}}
+
+	for _, format := range OutputFormats {
+		var buf bytes.Buffer
+		if err := WriteUploadResults(&buf, format, results, violations); err != nil {
+			t.Fatalf("%s: WriteUploadResults failed: %s", format, err)
+		}
+
+		var doc struct {
+			Uploaded       int
+			Total          int
+			Reports        []map[string]any
+			GateViolations []QualityGateViolation `json:"gateViolations" yaml:"gateViolations"`
+		}
+
+		var err error
+		if format == OutputFormatJSON {
+			err = json.Unmarshal(buf.Bytes(), &doc)
+		} else {
+			err = yaml.Unmarshal(buf.Bytes(), &doc)
+		}
+		if err != nil {
+			t.Fatalf("%s: output could not be parsed: %s\n%s", format, err, buf.String())
+		}
+
+		if doc.Uploaded != 1 || doc.Total != 2 || doc.Reports[0]["subtask"] != "EXAMPLE-16" || doc.Reports[0]["action"] != "created" {
+			t.Fatalf("%s: unexpected output:\n%s", format, buf.String())
+		}
+
+		if _, ok := doc.Reports[1]["subtask"]; ok || !strings.Contains(buf.String(), "passed") {
+			t.Fatalf("%s: unexpected output:\n%s", format, buf.String())
+		}
+
+		if len(doc.GateViolations) != 1 || doc.GateViolations[0] != violations[0] {
+			t.Fatalf("%s: unexpected gate violations:\n%s", format, buf.String())
+		}
+	}
+
+	if err := WriteUploadResults(&bytes.Buffer{}, "xml", results, nil); err == nil {
+		t.Fatalf("expected an error for an unknown output format")
+	}
+}
@@ -0,0 +1,173 @@
+package reporter
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retryableStatusCodes lists HTTP status codes returned by Jira for requests that may succeed when retried.
+var retryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// rateLimitResetLayouts lists the formats of timestamps used in the X-RateLimit-Reset header.
+var rateLimitResetLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00"}
+
+// sleep pauses the current goroutine for a given duration or until the context is done.
+// It can be replaced in tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// isRetryableRequest checks whether a request that failed with a given status code or error can be retried.
+// Only idempotent requests are retried, except for requests rejected due to rate limiting,
+// as these are never processed by Jira.
+func isRetryableRequest(method string, statusCode int, err error) bool {
+	if err != nil {
+		return isIdempotentMethod(method)
+	}
+
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return isIdempotentMethod(method) && slices.Contains(retryableStatusCodes, statusCode)
+}
+
+// backoff returns the delay before a given retry (starting at 1). The delay doubles with each retry,
+// starting at the initial backoff and never exceeding the max backoff, and up to a half of it is random.
+func (c JiraServerRetryConfig) backoff(retry int) time.Duration {
+	d := c.InitialBackoff
+	for i := 1; i < retry && (c.MaxBackoff <= 0 || d < c.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if c.MaxBackoff > 0 {
+		d = min(d, c.MaxBackoff)
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + rand.N(d/2+1)
+}
+
+// rateLimitDelay returns the delay requested by Jira with the Retry-After header or, if the rate limit
+// has been exhausted, with the X-RateLimit-Reset header. Both delays in seconds and timestamps are accepted.
+func rateLimitDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0), true
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+
+	if reset, ok := parseRateLimitReset(header.Get("X-RateLimit-Reset")); ok {
+		return max(reset.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+func parseRateLimitReset(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+
+	if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(epoch, 0), true
+	}
+
+	for _, layout := range rateLimitResetLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// rateLimiter pauses all requests sent by a client once Jira reports that the rate limit has been exhausted.
+// A nil rateLimiter never pauses requests.
+type rateLimiter struct {
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// update records the state of the rate limit reported in the headers of a response.
+func (l *rateLimiter) update(header http.Header) {
+	if l == nil || header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	reset, ok := parseRateLimitReset(header.Get("X-RateLimit-Reset"))
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if reset.After(l.pausedUntil) {
+		l.pausedUntil = reset
+	}
+}
+
+// wait blocks until the rate limit is expected to be reset or the context is done. Pauses longer than a given
+// max pause (if set) are capped, and the request is sent afterwards, so that it is retried if still rate limited.
+func (l *rateLimiter) wait(ctx context.Context, maxPause time.Duration) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	d := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+
+	if d <= 0 {
+		return nil
+	}
+
+	if maxPause > 0 {
+		d = min(d, maxPause)
+	}
+
+	// The pause affects all requests of the client, so it is not logged with the messages of a single report
+	InfoLog.Printf("Jira rate limit exhausted. Pausing requests for %s", d.Round(time.Second))
+	return sleep(ctx, d)
+}
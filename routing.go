@@ -0,0 +1,496 @@
+package reporter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/joshdk/go-junit"
+)
+
+var (
+	matchAllTestSuiteRules = []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol}}}
+	matchAllTestCaseRules  = []ReportingTestCaseConfig{{ReportingRuleConfig{Name: MatchAllSymbol}}}
+)
+
+// Compile validates the reporting configuration and compiles the patterns referenced by routing rules.
+// Rules that have not been compiled beforehand are compiled when they are first used for matching.
+// Rules that have already been compiled are left untouched.
+func (c *ReportingConfig) Compile() error {
+	if err := c.InputFormat.Validate(); err != nil {
+		return fmt.Errorf("inputFormat: %w", err)
+	}
+
+	if err := c.Deduplication.Policy.Validate(); err != nil {
+		return fmt.Errorf("deduplication: %w", err)
+	}
+
+	if c.MaxConcurrency < 0 {
+		return fmt.Errorf("maxConcurrency: expected a non-negative number, got %d", c.MaxConcurrency)
+	}
+
+	for i := range c.Routing {
+		route := &c.Routing[i]
+
+		if err := route.compile(); err != nil {
+			return fmt.Errorf("routing[%d]: %w", i, err)
+		}
+
+		if err := route.compileRules(fmt.Sprintf("routing[%d]", i)); err != nil {
+			return err
+		}
+
+		if err := route.validateDesiredState(c.Routing[:i]); err != nil {
+			return fmt.Errorf("routing[%d].desiredState: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDesiredState checks the desired state override of the route. Reports are uploaded with a single
+// desired state, so routes sharing the same destination must not define different ones.
+func (r *ReportingRouteConfig) validateDesiredState(previous []ReportingRouteConfig) error {
+	if r.DesiredState == nil {
+		return nil
+	}
+
+	desiredState, err := MergeDesiredState(JiraIssueDesiredStateConfig{}, r.DesiredState)
+	if err != nil {
+		return err
+	}
+
+	if err := desiredState.Bugs.OnPass.Validate(); err != nil {
+		return fmt.Errorf("bugs.onPass: %w", err)
+	}
+
+	for _, other := range previous {
+		if other.Destination == r.Destination && other.DesiredState != nil && !reflect.DeepEqual(other.DesiredState, r.DesiredState) {
+			return fmt.Errorf("differs from the desired state of another route with destination '%s'", r.Destination)
+		}
+	}
+
+	return nil
+}
+
+// DestinationTemplateData holds the data available to dynamic destination templates.
+type DestinationTemplateData struct {
+	TestSuite junit.Suite
+	TestCase  junit.Test
+}
+
+func (r *ReportingRouteConfig) compile() (err error) {
+	if r.destinationTemplate != nil || !strings.Contains(r.Destination, "{{") {
+		return nil
+	}
+
+	// Missing properties are rendered as empty strings, so that such Test Cases can be skipped
+	r.destinationTemplate, err = template.New("destination").Option("missingkey=zero").Parse(r.Destination)
+	if err != nil {
+		return fmt.Errorf("invalid destination template '%s': %w", r.Destination, err)
+	}
+
+	return nil
+}
+
+// resolveDestination renders the dynamic destination of the route for a given Test Case.
+func (r *ReportingRouteConfig) resolveDestination(suite junit.Suite, test junit.Test) (string, error) {
+	var buf bytes.Buffer
+	data := DestinationTemplateData{
+		TestSuite: suite,
+		TestCase:  test,
+	}
+
+	if err := r.destinationTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("destination template '%s' could not be rendered: %w", r.Destination, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// compileRules compiles the Test Suite and exclusion rules of the route.
+func (r *ReportingRouteConfig) compileRules(path string) error {
+	if err := compileTestSuiteRules(r.TestSuites, path+".testSuites"); err != nil {
+		return err
+	}
+
+	return compileTestSuiteRules(r.Exclude, path+".exclude")
+}
+
+func compileTestSuiteRules(rules []ReportingTestSuiteConfig, path string) error {
+	for i := range rules {
+		rule := &rules[i]
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", path, i, err)
+		}
+
+		if err := compileTestCaseRules(rule.TestCases, fmt.Sprintf("%s[%d].testCases", path, i)); err != nil {
+			return err
+		}
+
+		if err := compileTestCaseRules(rule.Exclude, fmt.Sprintf("%s[%d].exclude", path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compileTestCaseRules(rules []ReportingTestCaseConfig, path string) error {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", path, i, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *ReportingRuleConfig) compile() (err error) {
+	if r.compiled {
+		return nil
+	}
+
+	if r.NameRegex != "" {
+		r.nameRegex, err = regexp.Compile(r.NameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid nameRegex '%s': %w", r.NameRegex, err)
+		}
+	}
+
+	if r.NameGlob != "" {
+		r.nameGlob, err = compileGlob(r.NameGlob)
+		if err != nil {
+			return fmt.Errorf("invalid nameGlob '%s': %w", r.NameGlob, err)
+		}
+	}
+
+	if r.Property != "" {
+		r.property, err = parsePropertyExpression(r.Property)
+		if err != nil {
+			return fmt.Errorf("invalid property expression '%s': %w", r.Property, err)
+		}
+	}
+
+	if r.PropertyRegex != "" {
+		name, pattern, found := strings.Cut(r.PropertyRegex, "=")
+		if !found || name == "" {
+			return fmt.Errorf("invalid propertyRegex '%s': expected the 'name=pattern' format", r.PropertyRegex)
+		}
+
+		r.propertyRegexName = name
+		r.propertyRegexValue, err = regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid propertyRegex '%s': %w", r.PropertyRegex, err)
+		}
+	}
+
+	r.compiled = true
+
+	return nil
+}
+
+// compileGlob converts a glob pattern into an anchored regular expression.
+// The '*' symbol matches any sequence of characters and '?' matches any single character.
+// All other characters, including brackets, are matched literally unless escaped with '\'.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	escaped := false
+	for _, r := range glob {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			sb.WriteString(".*")
+		case r == '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("pattern ends with an unterminated escape sequence")
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// matches checks whether an entity with the given name and properties satisfies any of the criteria of the rule.
+func (r *ReportingRuleConfig) matches(name string, properties map[string]string) bool {
+	return isEntityMatchedByNameRule(name, r.Name) ||
+		isEntityMatchedByPattern(name, r.nameRegex) ||
+		isEntityMatchedByPattern(name, r.nameGlob) ||
+		isEntityMatchedByPropertyExpression(properties, r.property) ||
+		isEntityMatchedByPropertyPattern(properties, r.propertyRegexName, r.propertyRegexValue)
+}
+
+func isEntityMatchedByNameRule(entityName string, nameRule string) bool {
+	if nameRule == "" {
+		return false
+	}
+
+	return nameRule == MatchAllSymbol || nameRule == entityName
+}
+
+func isEntityMatchedByPattern(entityName string, pattern *regexp.Regexp) bool {
+	if pattern == nil {
+		return false
+	}
+
+	return pattern.MatchString(entityName)
+}
+
+func isEntityMatchedByPropertyExpression(entityProperties map[string]string, expr propertyExpression) bool {
+	if expr == nil {
+		return false
+	}
+
+	return expr.evaluate(entityProperties)
+}
+
+func isEntityMatchedByPropertyPattern(entityProperties map[string]string, name string, pattern *regexp.Regexp) bool {
+	if pattern == nil {
+		return false
+	}
+
+	value, ok := entityProperties[name]
+	if !ok {
+		return false
+	}
+
+	return pattern.MatchString(value)
+}
+
+// ruleIndex narrows down the rules that have to be evaluated for an entity with a given name.
+// Rules that only match a single, exact name are looked up by that name, while all other rules
+// (match-all rules, patterns and property expressions) are evaluated for every entity.
+type ruleIndex struct {
+	rules  []*ReportingRuleConfig
+	byName map[string][]int
+	other  []int
+}
+
+func newRuleIndex(rules []*ReportingRuleConfig) *ruleIndex {
+	idx := &ruleIndex{rules: rules, byName: map[string][]int{}}
+	for i, rule := range rules {
+		if rule.isExactNameRule() {
+			idx.byName[rule.Name] = append(idx.byName[rule.Name], i)
+		} else {
+			idx.other = append(idx.other, i)
+		}
+	}
+
+	return idx
+}
+
+func testSuiteRuleIndex(rules []ReportingTestSuiteConfig) *ruleIndex {
+	return newRuleIndex(testSuiteRuleConfigs(rules))
+}
+
+func testSuiteRuleConfigs(rules []ReportingTestSuiteConfig) []*ReportingRuleConfig {
+	ptrs := make([]*ReportingRuleConfig, len(rules))
+	for i := range rules {
+		ptrs[i] = &rules[i].ReportingRuleConfig
+	}
+
+	return ptrs
+}
+
+func testCaseRuleIndex(rules []ReportingTestCaseConfig) *ruleIndex {
+	ptrs := make([]*ReportingRuleConfig, len(rules))
+	for i := range rules {
+		ptrs[i] = &rules[i].ReportingRuleConfig
+	}
+
+	return newRuleIndex(ptrs)
+}
+
+// isExactNameRule checks whether the rule matches nothing but a single, exact name.
+func (r *ReportingRuleConfig) isExactNameRule() bool {
+	return r.Name != "" && r.Name != MatchAllSymbol &&
+		r.NameRegex == "" && r.NameGlob == "" && r.Property == "" && r.PropertyRegex == ""
+}
+
+// matchesAny checks whether any of the indexed rules matches an entity with the given name and properties.
+func (idx *ruleIndex) matchesAny(name string, properties map[string]string) bool {
+	if len(idx.byName[name]) > 0 {
+		return true
+	}
+
+	for _, i := range idx.other {
+		if idx.rules[i].matches(name, properties) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matching returns the indices of all indexed rules that match an entity with the given name and properties.
+func (idx *ruleIndex) matching(name string, properties map[string]string) []int {
+	indices := slices.Clone(idx.byName[name])
+	for _, i := range idx.other {
+		if idx.rules[i].matches(name, properties) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// testCaseSelector describes which Test Cases of a matched Test Suite should be added to a report.
+type testCaseSelector struct {
+	include *ruleIndex
+	exclude []*ruleIndex
+}
+
+// selects checks whether a Test Case is matched by any of the inclusion rules and none of the exclusion rules.
+func (s testCaseSelector) selects(test junit.Test) bool {
+	for _, exclude := range s.exclude {
+		if exclude.matchesAny(test.Name, test.Properties) {
+			return false
+		}
+	}
+
+	return s.include.matchesAny(test.Name, test.Properties)
+}
+
+// routeMatcher selects Test Cases for a single route. The Test Case rules of the route are indexed when
+// the matcher is created, so that the cost of matching does not grow with the number of rules that match
+// only exact names. Test Suite rules are indexed together with the rules of all other routes by suiteRuleIndex.
+type routeMatcher struct {
+	route      ReportingRouteConfig
+	suites     []*ReportingRuleConfig
+	suiteRules []testSuiteRuleMatcher
+}
+
+type testSuiteRuleMatcher struct {
+	include *ruleIndex
+	exclude *ruleIndex
+
+	// Route-level exclusion rules and their Test Case rules. A nil index excludes the whole Test Suite
+	routeExclude          *ruleIndex
+	routeExcludeTestCases []*ruleIndex
+}
+
+func newRouteMatcher(route ReportingRouteConfig) *routeMatcher {
+	suiteRules := route.TestSuites
+	if suiteRules == nil {
+		// Apply a Match-All rule when a route has no been configured
+		suiteRules = matchAllTestSuiteRules
+	}
+
+	m := &routeMatcher{
+		route:  route,
+		suites: testSuiteRuleConfigs(suiteRules),
+	}
+
+	for _, rule := range suiteRules {
+		include := rule.TestCases
+		if include == nil {
+			include = matchAllTestCaseRules
+		}
+
+		routeExclude := slices.Concat(rule.routeExclude, route.Exclude)
+		sm := testSuiteRuleMatcher{
+			include:      testCaseRuleIndex(include),
+			exclude:      testCaseRuleIndex(rule.Exclude),
+			routeExclude: testSuiteRuleIndex(routeExclude),
+		}
+
+		for _, exclude := range routeExclude {
+			var idx *ruleIndex
+			if exclude.TestCases != nil {
+				idx = testCaseRuleIndex(exclude.TestCases)
+			}
+			sm.routeExcludeTestCases = append(sm.routeExcludeTestCases, idx)
+		}
+
+		m.suiteRules = append(m.suiteRules, sm)
+	}
+
+	return m
+}
+
+// selectTestCases returns a selector for the Test Cases of a given Test Suite for each of the given Test Suite rules
+// matching it. Exclusion rules are evaluated after the inclusion rules. If any of the route-level exclusion rules
+// matches the Test Suite and does not narrow the exclusion down to specific Test Cases, the Test Suite rule is skipped.
+func (m *routeMatcher) selectTestCases(suite junit.Suite, matched []int) (selectors []testCaseSelector) {
+rules:
+	for _, i := range matched {
+		rule := m.suiteRules[i]
+		s := testCaseSelector{
+			include: rule.include,
+			exclude: []*ruleIndex{rule.exclude},
+		}
+
+		for _, j := range rule.routeExclude.matching(suite.Name, suite.Properties) {
+			if rule.routeExcludeTestCases[j] == nil {
+				continue rules
+			}
+			s.exclude = append(s.exclude, rule.routeExcludeTestCases[j])
+		}
+
+		selectors = append(selectors, s)
+	}
+
+	return selectors
+}
+
+// suiteRuleRef identifies a Test Suite rule of a route.
+type suiteRuleRef struct {
+	route int
+	rule  int
+}
+
+// suiteRuleIndex looks up the Test Suite rules of all routes at once, so that the cost of routing a Test Suite
+// does not grow with the number of routes whose rules match only exact names.
+type suiteRuleIndex struct {
+	rules *ruleIndex
+	refs  []suiteRuleRef
+}
+
+func newSuiteRuleIndex(matchers []*routeMatcher) *suiteRuleIndex {
+	var rules []*ReportingRuleConfig
+	idx := &suiteRuleIndex{}
+	for i, m := range matchers {
+		for j, rule := range m.suites {
+			rules = append(rules, rule)
+			idx.refs = append(idx.refs, suiteRuleRef{route: i, rule: j})
+		}
+	}
+	idx.rules = newRuleIndex(rules)
+
+	return idx
+}
+
+// matching returns the rules matching a Test Suite, grouped by their route. Routes and their rules
+// are kept in the order they were configured in.
+func (idx *suiteRuleIndex) matching(suite junit.Suite) (routes []int, rules [][]int) {
+	indices := idx.rules.matching(suite.Name, suite.Properties)
+	slices.Sort(indices)
+
+	for _, i := range indices {
+		ref := idx.refs[i]
+		if len(routes) == 0 || routes[len(routes)-1] != ref.route {
+			routes = append(routes, ref.route)
+			rules = append(rules, nil)
+		}
+		rules[len(rules)-1] = append(rules[len(rules)-1], ref.rule)
+	}
+
+	return routes, rules
+}
@@ -0,0 +1,455 @@
+package reporter
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+)
+
+func createRoutingTestSuites() []junit.Suite {
+	return []junit.Suite{
+		{
+			Name:       "[sig-network] Networking",
+			Properties: map[string]string{"platform": "baremetal"},
+			Tests: []junit.Test{
+				{Name: "[sig-network] should reach a service", Status: junit.StatusPassed},
+				{Name: "[sig-network] should resolve DNS", Status: junit.StatusFailed},
+				{Name: "[sig-storage] should mount a volume", Status: junit.StatusPassed, Properties: map[string]string{"polarion-testcase-id": "POL-120"}},
+			},
+		},
+		{
+			Name: "[sig-storage] Storage",
+			Tests: []junit.Test{
+				{Name: "[sig-storage] should provision a volume", Status: junit.StatusSkipped, Properties: map[string]string{"polarion-testcase-id": "POL-250"}},
+			},
+		},
+	}
+}
+
+func TestReportingConfigCompile(t *testing.T) {
+	invalidRules := map[string]ReportingRuleConfig{
+		"invalid regex":                 {NameRegex: "[sig-network"},
+		"unterminated glob escape":      {NameGlob: `[sig-network] \`},
+		"property regex without name":   {PropertyRegex: "=POL-.*"},
+		"property regex without value":  {PropertyRegex: "polarion-testcase-id"},
+		"property regex invalid syntax": {PropertyRegex: "polarion-testcase-id=(POL"},
+	}
+
+	for desc, rule := range invalidRules {
+		config := ReportingConfig{Routing: []ReportingRouteConfig{{
+			Destination: "EXAMPLE-15",
+			TestSuites: []ReportingTestSuiteConfig{{
+				ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+				TestCases:           []ReportingTestCaseConfig{{rule}},
+			}},
+		}}}
+
+		if err := config.Compile(); err == nil {
+			t.Fatalf("Compile should have failed: %s", desc)
+		}
+	}
+}
+
+func TestProcessJUnitSuitesWithUncompiledRules(t *testing.T) {
+	suites := createRoutingTestSuites()
+
+	route := ReportingRouteConfig{
+		Destination: "EXAMPLE-15",
+		TestSuites: []ReportingTestSuiteConfig{{
+			ReportingRuleConfig: ReportingRuleConfig{NameGlob: "[sig-network] *"},
+			TestCases:           []ReportingTestCaseConfig{{ReportingRuleConfig{NameRegex: "DNS"}}},
+		}},
+	}
+
+	report, err := ProcessJUnitSuites(suites, route)
+	if err != nil {
+		t.Fatalf("ProcessJUnitSuites failed: %s", err)
+	}
+	if expected := (Counts{Failed: 1, Total: 1}); report.Counts != expected {
+		t.Fatalf("expected counts %+v, got %+v", expected, report.Counts)
+	}
+
+	reports, err := ProcessJUnitRoutes(suites, []ReportingRouteConfig{route})
+	if err != nil {
+		t.Fatalf("ProcessJUnitRoutes failed: %s", err)
+	}
+	if len(reports) != 1 || reports[0].Counts != report.Counts {
+		t.Fatalf("expected a single report with counts %+v, got %+v", report.Counts, reports)
+	}
+
+	invalid := ReportingRouteConfig{
+		Destination: "EXAMPLE-15",
+		TestSuites:  []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{NameRegex: "[sig-network"}}},
+	}
+
+	if _, err := ProcessJUnitSuites(suites, invalid); err == nil {
+		t.Fatalf("ProcessJUnitSuites should have failed for an invalid rule")
+	}
+
+	if _, err := ProcessJUnitRoutes(suites, []ReportingRouteConfig{invalid}); err == nil {
+		t.Fatalf("ProcessJUnitRoutes should have failed for an invalid rule")
+	}
+}
+
+func TestProcessJUnitSuitesWithPatterns(t *testing.T) {
+	suites := createRoutingTestSuites()
+
+	testCases := []struct {
+		desc     string
+		rule     ReportingTestSuiteConfig
+		expected Counts
+	}{
+		{
+			desc:     "suite matched by glob with literal brackets",
+			rule:     ReportingTestSuiteConfig{ReportingRuleConfig: ReportingRuleConfig{NameGlob: "[sig-network] *"}},
+			expected: Counts{Passed: 2, Failed: 1, Total: 3},
+		},
+		{
+			desc: "test cases matched by glob",
+			rule: ReportingTestSuiteConfig{
+				ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+				TestCases:           []ReportingTestCaseConfig{{ReportingRuleConfig{NameGlob: "[sig-storage] should ?rovision*"}}},
+			},
+			expected: Counts{Skipped: 1, Total: 1},
+		},
+		{
+			desc:     "suite matched by regex",
+			rule:     ReportingTestSuiteConfig{ReportingRuleConfig: ReportingRuleConfig{NameRegex: "^\\[sig-storage\\]"}},
+			expected: Counts{Skipped: 1, Total: 1},
+		},
+		{
+			desc: "test cases matched by property regex",
+			rule: ReportingTestSuiteConfig{
+				ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+				TestCases:           []ReportingTestCaseConfig{{ReportingRuleConfig{PropertyRegex: "polarion-testcase-id=^POL-1[0-9]{2}$"}}},
+			},
+			expected: Counts{Passed: 1, Total: 1},
+		},
+		{
+			// Test Suites matched by property used to contribute no Test Cases without any Test Case rules
+			desc:     "suite matched by property without test case rules",
+			rule:     ReportingTestSuiteConfig{ReportingRuleConfig: ReportingRuleConfig{Property: "platform=baremetal"}},
+			expected: Counts{Passed: 2, Failed: 1, Total: 3},
+		},
+		{
+			// Test Cases used to be counted once for each rule matching them
+			desc: "test cases matched by multiple rules are counted once",
+			rule: ReportingTestSuiteConfig{
+				ReportingRuleConfig: ReportingRuleConfig{PropertyRegex: "platform=metal"},
+				TestCases: []ReportingTestCaseConfig{
+					{ReportingRuleConfig{NameGlob: "*"}},
+					{ReportingRuleConfig{NameRegex: "DNS"}},
+				},
+			},
+			expected: Counts{Passed: 2, Failed: 1, Total: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		config := ReportingConfig{Routing: []ReportingRouteConfig{{
+			Destination: "EXAMPLE-15",
+			TestSuites:  []ReportingTestSuiteConfig{tc.rule},
+		}}}
+		if err := config.Compile(); err != nil {
+			t.Fatalf("%s: Compile failed: %s", tc.desc, err)
+		}
+
+		report, err := ProcessJUnitSuites(suites, config.Routing[0])
+		if err != nil {
+			t.Fatalf("%s: ProcessJUnitSuites failed: %s", tc.desc, err)
+		}
+		if report.Counts != tc.expected {
+			t.Fatalf("%s: expected counts %+v, got %+v", tc.desc, tc.expected, report.Counts)
+		}
+	}
+}
+
+func TestProcessJUnitSuitesWithExclusions(t *testing.T) {
+	suites := createRoutingTestSuites()
+
+	testCases := []struct {
+		desc     string
+		routes   []ReportingRouteConfig
+		expected Counts
+	}{
+		{
+			desc: "whole suite excluded at route level",
+			routes: []ReportingRouteConfig{{
+				Exclude: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{NameGlob: "[sig-network] *"}}},
+			}},
+			expected: Counts{Skipped: 1, Total: 1},
+		},
+		{
+			desc: "test cases excluded at route level",
+			routes: []ReportingRouteConfig{{
+				Exclude: []ReportingTestSuiteConfig{{
+					ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+					TestCases:           []ReportingTestCaseConfig{{ReportingRuleConfig{NameGlob: "[sig-storage] *"}}},
+				}},
+			}},
+			expected: Counts{Passed: 1, Failed: 1, Total: 2},
+		},
+		{
+			desc: "test cases excluded at test suite level",
+			routes: []ReportingRouteConfig{{
+				TestSuites: []ReportingTestSuiteConfig{{
+					ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+					Exclude:             []ReportingTestCaseConfig{{ReportingRuleConfig{Property: "polarion-testcase-id=POL-120"}}},
+				}},
+			}},
+			expected: Counts{Passed: 1, Failed: 1, Skipped: 1, Total: 3},
+		},
+		{
+			desc: "route-level exclusions do not affect other routes with the same destination",
+			routes: []ReportingRouteConfig{
+				{
+					TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{NameGlob: "[sig-network] *"}}},
+					Exclude:    []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol}}},
+				},
+				{
+					TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{NameGlob: "[sig-storage] *"}}},
+				},
+			},
+			expected: Counts{Skipped: 1, Total: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		for i := range tc.routes {
+			tc.routes[i].Destination = "EXAMPLE-15"
+		}
+
+		config := ReportingConfig{Routing: tc.routes}
+		if err := config.Compile(); err != nil {
+			t.Fatalf("%s: Compile failed: %s", tc.desc, err)
+		}
+
+		routes := groupRouteConfigsByDestination(config.Routing)
+		if len(routes) != 1 {
+			t.Fatalf("%s: expected routes to be grouped into 1 destination, got %d", tc.desc, len(routes))
+		}
+
+		report, err := ProcessJUnitSuites(suites, routes[0])
+		if err != nil {
+			t.Fatalf("%s: ProcessJUnitSuites failed: %s", tc.desc, err)
+		}
+		if report.Counts != tc.expected {
+			t.Fatalf("%s: expected counts %+v, got %+v", tc.desc, tc.expected, report.Counts)
+		}
+	}
+}
+
+func TestProcessJUnitRoutesWithDynamicDestinations(t *testing.T) {
+	suites := []junit.Suite{
+		{
+			Name:       "Networking",
+			Properties: map[string]string{"jira_story": "EXAMPLE-20"},
+			Tests: []junit.Test{
+				{Name: "one", Status: junit.StatusPassed, Properties: map[string]string{"jira-story": "EXAMPLE-15"}},
+				{Name: "two", Status: junit.StatusFailed, Properties: map[string]string{"jira-story": "EXAMPLE-15"}},
+				{Name: "three", Status: junit.StatusPassed},
+			},
+		},
+		{
+			Name: "Storage",
+			Tests: []junit.Test{
+				{Name: "four", Status: junit.StatusPassed, Properties: map[string]string{"jira-story": "EXAMPLE-25"}},
+				{Name: "five", Status: junit.StatusPassed},
+			},
+		},
+	}
+
+	config := ReportingConfig{Routing: []ReportingRouteConfig{
+		{Destination: `{{ index .TestCase.Properties "jira-story" }}`},
+		{Destination: `{{ .TestSuite.Properties.jira_story }}`},
+		{
+			Destination: "EXAMPLE-15",
+			TestSuites:  []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "Networking"}}},
+		},
+	}}
+	if err := config.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	reports, err := ProcessJUnitRoutes(suites, config.Routing)
+	if err != nil {
+		t.Fatalf("ProcessJUnitRoutes failed: %s", err)
+	}
+
+	// Test Cases of the Storage suite without the property are not reported, as the suite does not set it
+	expected := map[string]Counts{
+		"EXAMPLE-15": {Passed: 2, Failed: 1, Total: 3},
+		"EXAMPLE-20": {Passed: 2, Failed: 1, Total: 3},
+		"EXAMPLE-25": {Passed: 1, Total: 1},
+	}
+	if len(reports) != len(expected) {
+		t.Fatalf("expected %d reports, got %d: %+v", len(expected), len(reports), reports)
+	}
+
+	for _, report := range reports {
+		if report.Counts != expected[report.Destination] {
+			t.Fatalf("expected counts %+v for %s, got %+v", expected[report.Destination], report.Destination, report.Counts)
+		}
+	}
+
+	invalidConfig := ReportingConfig{Routing: []ReportingRouteConfig{{Destination: "{{ .TestCase.Name "}}}
+	if err := invalidConfig.Compile(); err == nil {
+		t.Fatalf("Compile should have failed for an invalid destination template")
+	}
+}
+
+func TestProcessJUnitRoutesWithDiscoveredRoutes(t *testing.T) {
+	suites := createRoutingTestSuites()
+
+	rules := []ReportingTestSuiteConfig{{
+		ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol},
+		TestCases:           []ReportingTestCaseConfig{{ReportingRuleConfig{Property: "polarion-testcase-id=POL-250"}}},
+	}}
+	config := ReportingConfig{Routing: []ReportingRouteConfig{
+		{Destination: "EXAMPLE-15", TestSuites: rules},
+		{Destination: "EXAMPLE-20", TestSuites: rules, discovered: true},
+	}}
+	if err := config.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	reports, err := ProcessJUnitRoutes(suites, config.Routing)
+	if err != nil {
+		t.Fatalf("ProcessJUnitRoutes failed: %s", err)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d: %+v", len(reports), reports)
+	}
+
+	// Static routes report all matched Test Suites, even those without any selected Test Cases
+	if suites := reports[0].TestSuites; len(suites) != 2 || len(suites[0].TestCases) != 0 || len(suites[1].TestCases) != 1 {
+		t.Fatalf("unexpected test suites for %s: %+v", reports[0].Destination, suites)
+	}
+
+	if suites := reports[1].TestSuites; len(suites) != 1 || suites[0].Name != "[sig-storage] Storage" {
+		t.Fatalf("unexpected test suites for %s: %+v", reports[1].Destination, suites)
+	}
+}
+
+func TestProcessJUnitRoutesWithDesiredStates(t *testing.T) {
+	suites := createRoutingTestSuites()
+
+	teamA := JiraIssueDesiredStateOverrideConfig{"summary": map[string]any{"contents": "Team A test results"}}
+
+	config := ReportingConfig{Routing: []ReportingRouteConfig{
+		{Destination: "EXAMPLE-15", TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "[sig-network] Networking"}}}},
+		{Destination: "EXAMPLE-15", TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "[sig-storage] Storage"}}}, DesiredState: teamA},
+		{Destination: "EXAMPLE-20"},
+	}}
+	if err := config.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	reports, err := ProcessJUnitRoutes(suites, config.Routing)
+	if err != nil {
+		t.Fatalf("ProcessJUnitRoutes failed: %s", err)
+	}
+
+	// The desired state applies to the whole report, including results of other routes with the same destination
+	if len(reports) != 2 || !reflect.DeepEqual(reports[0].DesiredState, teamA) || reports[0].Counts.Total != 4 || reports[1].DesiredState != nil {
+		t.Fatalf("expected the desired state to be set on the report of EXAMPLE-15 only, got %+v", reports)
+	}
+
+	teamB := JiraIssueDesiredStateOverrideConfig{"summary": map[string]any{"contents": "Team B test results"}}
+	conflicting := ReportingConfig{Routing: []ReportingRouteConfig{
+		{Destination: "EXAMPLE-15", DesiredState: teamA},
+		{Destination: "EXAMPLE-15", DesiredState: teamB},
+	}}
+	if err := conflicting.Compile(); err == nil || err.Error() != "routing[1].desiredState: differs from the desired state of another route with destination 'EXAMPLE-15'" {
+		t.Fatalf("Compile should have failed for routes with different desired states, got %v", err)
+	}
+
+	for _, desiredState := range []JiraIssueDesiredStateOverrideConfig{
+		{"bugs": map[string]any{"onPass": "reopen"}},
+		{"onSuccess": map[string]any{"labels": map[string]any{"passed": true}}},
+	} {
+		invalid := ReportingConfig{Routing: []ReportingRouteConfig{{Destination: "EXAMPLE-15", DesiredState: desiredState}}}
+		if err := invalid.Compile(); err == nil {
+			t.Fatalf("Compile should have failed for an invalid desired state %v", desiredState)
+		}
+	}
+}
+
+func TestRuleIndex(t *testing.T) {
+	rules := []ReportingTestCaseConfig{
+		{ReportingRuleConfig{Name: "one"}},
+		{ReportingRuleConfig{Name: "two", NameGlob: "t*"}},
+		{ReportingRuleConfig{Property: "EXISTS polarion-testcase-id"}},
+		{ReportingRuleConfig{Name: "one"}},
+	}
+	config := ReportingConfig{Routing: []ReportingRouteConfig{{
+		TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: MatchAllSymbol}, TestCases: rules}},
+	}}}
+	if err := config.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	idx := testCaseRuleIndex(config.Routing[0].TestSuites[0].TestCases)
+	if len(idx.byName["one"]) != 2 || len(idx.other) != 2 {
+		t.Fatalf("expected only exact name rules to be indexed by name, got %+v", idx)
+	}
+
+	testCases := []struct {
+		name       string
+		properties map[string]string
+		expected   int
+	}{
+		{name: "one", expected: 2},
+		{name: "three", expected: 1},
+		{name: "four", properties: map[string]string{"polarion-testcase-id": "POL-120"}, expected: 1},
+		{name: "one", properties: map[string]string{"polarion-testcase-id": "POL-120"}, expected: 3},
+		{name: "four", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		if matching := idx.matching(tc.name, tc.properties); len(matching) != tc.expected {
+			t.Fatalf("expected %d rules to match '%s' %v, got %v", tc.expected, tc.name, tc.properties, matching)
+		}
+
+		if idx.matchesAny(tc.name, tc.properties) != (tc.expected > 0) {
+			t.Fatalf("matchesAny is inconsistent with matching for '%s' %v", tc.name, tc.properties)
+		}
+	}
+}
+
+func TestSuiteRuleIndex(t *testing.T) {
+	config := ReportingConfig{Routing: []ReportingRouteConfig{
+		{Destination: "EXAMPLE-1", TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "Storage"}}}},
+		{Destination: "EXAMPLE-2", TestSuites: []ReportingTestSuiteConfig{
+			{ReportingRuleConfig: ReportingRuleConfig{Name: "Networking"}},
+			{ReportingRuleConfig: ReportingRuleConfig{NameGlob: "Net*"}},
+		}},
+		{Destination: "EXAMPLE-3", TestSuites: []ReportingTestSuiteConfig{{ReportingRuleConfig: ReportingRuleConfig{Name: "Networking"}}}},
+	}}
+	if err := config.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	var matchers []*routeMatcher
+	for _, route := range config.Routing {
+		matchers = append(matchers, newRouteMatcher(route))
+	}
+	idx := newSuiteRuleIndex(matchers)
+
+	// Exact names of all routes are looked up at once, only the pattern is evaluated for each Test Suite
+	if len(idx.rules.byName["Networking"]) != 2 || len(idx.rules.other) != 1 {
+		t.Fatalf("expected exact name rules of all routes to be indexed by name, got %+v", idx.rules)
+	}
+
+	routes, rules := idx.matching(junit.Suite{Name: "Networking"})
+	if !slices.Equal(routes, []int{1, 2}) || len(rules) != 2 || !slices.Equal(rules[0], []int{0, 1}) || !slices.Equal(rules[1], []int{0}) {
+		t.Fatalf("expected the rules to be grouped by their route in the configured order, got %v and %v", routes, rules)
+	}
+
+	if routes, _ := idx.matching(junit.Suite{Name: "Compute"}); len(routes) != 0 {
+		t.Fatalf("expected no routes to match, got %v", routes)
+	}
+}
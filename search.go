@@ -0,0 +1,93 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	jiraSearchEndpoint      = "/rest/api/2/search"
+	jiraCloudSearchEndpoint = "/rest/api/3/search/jql"
+
+	// jiraSearchPageSize is the number of issues fetched with a single search request.
+	jiraSearchPageSize = 100
+)
+
+// apiResponseSearch holds a page of search results. Jira Data Center pages the results with startAt
+// and total, while Jira Cloud returns a token for the next page.
+type apiResponseSearch struct {
+	StartAt       int                 `json:"startAt"`
+	Total         int                 `json:"total"`
+	Issues        []*apiResponseIssue `json:"issues"`
+	NextPageToken string              `json:"nextPageToken"`
+	IsLast        bool                `json:"isLast"`
+}
+
+// SearchIssues sends requests to Jira REST API to fetch all Issues matching a given JQL query.
+// The results are fetched page by page until all matching Issues have been returned.
+func (c JiraClient) SearchIssues(ctx context.Context, jql string) (issues []JiraIssue, err error) {
+	infoLog(ctx).Printf("Searching Jira issues (%s)", jql)
+
+	endpoint := jiraSearchEndpoint
+	if c.Flavor == JiraFlavorCloud {
+		endpoint = jiraCloudSearchEndpoint
+	}
+
+	endpointURL, err := url.JoinPath(c.ServerURL, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"jql":        {jql},
+		"maxResults": {strconv.Itoa(jiraSearchPageSize)},
+		"fields":     {"*navigable"},
+	}
+
+	for {
+		if c.Flavor != JiraFlavorCloud {
+			query.Set("startAt", strconv.Itoa(len(issues)))
+		}
+
+		resp, err := c.sendRequest(ctx, "GET", endpointURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("issues could not be searched. HTTP status code: %d", resp.StatusCode)
+		}
+
+		var page apiResponseSearch
+		if err := json.Unmarshal(resp.Body, &page); err != nil {
+			return nil, err
+		}
+
+		for _, result := range page.Issues {
+			issue, err := result.JiraIssue()
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, issue)
+		}
+
+		if c.Flavor == JiraFlavorCloud {
+			if page.IsLast || page.NextPageToken == "" {
+				return issues, nil
+			}
+			query.Set("nextPageToken", page.NextPageToken)
+		} else if len(page.Issues) == 0 || len(issues) >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// jqlQuote quotes a value so that it can be safely used in a JQL query.
+func jqlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchIssues(t *testing.T) {
+	jira, server := newFakeJira(t)
+	for _, key := range []string{"EXAMPLE-1", "EXAMPLE-2", "EXAMPLE-3", "EXAMPLE-4", "EXAMPLE-5"} {
+		jira.addIssue(key, "Story", "QE Story", []string{"TELCO-V10N-ST"}, "")
+	}
+	jira.addIssue("EXAMPLE-6", "Story", "QE Story", nil, "")
+	jira.addIssue("OTHER-1", "Story", "QE Story", []string{"TELCO-V10N-ST"}, "")
+
+	// Results are paged with startAt on Jira Data Center, and with page tokens on Jira Cloud
+	for _, flavor := range []JiraFlavor{JiraFlavorDataCenter, JiraFlavorCloud} {
+		config := newTestJiraServerConfig(server.URL)
+		config.Flavor = flavor
+		config.Email = "user@example.com"
+
+		client, err := NewJiraClient(config)
+		if err != nil {
+			t.Fatalf("NewJiraClient failed: %s", err)
+		}
+
+		issues, err := client.SearchIssues(context.Background(), `project = "EXAMPLE" AND labels = "TELCO-V10N-ST"`)
+		if err != nil {
+			t.Fatalf("SearchIssues failed: %s", err)
+		}
+
+		var keys []string
+		for _, issue := range issues {
+			keys = append(keys, issue.ID)
+		}
+
+		if len(keys) != 5 || keys[0] != "EXAMPLE-1" || keys[4] != "EXAMPLE-5" {
+			t.Fatalf("%s: expected all 5 matching issues, got %v", flavor, keys)
+		}
+	}
+
+	client := newTestJiraClient(t, server.URL)
+	if _, err := client.SearchIssues(context.Background(), "summary ~ QE"); err == nil {
+		t.Fatalf("expected an error for a rejected query")
+	}
+}
+
+func TestJQLQuote(t *testing.T) {
+	if quoted := jqlQuote(`say "hi" \o/`); quoted != `"say \"hi\" \\o/"` {
+		t.Fatalf("unexpected quoted value: %s", quoted)
+	}
+}
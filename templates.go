@@ -3,9 +3,31 @@ package reporter
 import (
 	"bytes"
 	"io/fs"
+	"path/filepath"
+	"strings"
 	"text/template"
 )
 
+// templateFuncs defines helper functions available to all templates.
+var templateFuncs = template.FuncMap{
+	"jiraTableCell": jiraTableCell,
+}
+
+// jiraTableCell turns a given string into a single line that can be safely placed
+// in a table cell using the Jira wiki markup.
+func jiraTableCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	replacer := strings.NewReplacer(
+		"|", `\|`,
+		"{", `\{`,
+		"}", `\}`,
+		"[", `\[`,
+		"]", `\]`,
+	)
+	return replacer.Replace(s)
+}
+
 func RenderLocalTemplate(path string, data any) (buf bytes.Buffer, err error) {
 	return renderTemplate(nil, path, data)
 }
@@ -15,12 +37,12 @@ func RenderEmbeddedTemplate(path string, data any) (buf bytes.Buffer, err error)
 }
 
 func renderTemplate(fs fs.FS, path string, data any) (buf bytes.Buffer, err error) {
-	var tmpl *template.Template
+	tmpl := template.New(filepath.Base(path)).Funcs(templateFuncs)
 
 	if fs != nil {
-		tmpl, err = template.ParseFS(fs, path)
+		tmpl, err = tmpl.ParseFS(fs, path)
 	} else {
-		tmpl, err = template.ParseFiles(path)
+		tmpl, err = tmpl.ParseFiles(path)
 	}
 
 	if err != nil {
@@ -0,0 +1,84 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// JiraTransition represents a workflow transition available for an Issue in its current status.
+type JiraTransition struct {
+	ID       string
+	Name     string
+	ToStatus string
+}
+
+type apiResponseTransition struct {
+	ID   string                 `json:"id"`
+	Name string                 `json:"name"`
+	To   apiResponseIssueStatus `json:"to"`
+}
+
+type apiResponseTransitions struct {
+	Transitions []apiResponseTransition `json:"transitions"`
+}
+
+// GetTransitions sends a request to Jira REST API to fetch the workflow transitions
+// available for an Issue with the given ID in its current status.
+func (c JiraClient) GetTransitions(ctx context.Context, id string) (transitions []JiraTransition, err error) {
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id, "transitions")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.sendRequest(ctx, "GET", endpointURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("transitions of issue %s could not be fetched. HTTP status code: %d", id, resp.StatusCode)
+	}
+
+	var data apiResponseTransitions
+	if err := json.Unmarshal(resp.Body, &data); err != nil {
+		return nil, err
+	}
+
+	for _, transition := range data.Transitions {
+		transitions = append(transitions, JiraTransition{ID: transition.ID, Name: transition.Name, ToStatus: transition.To.Name})
+	}
+
+	return transitions, nil
+}
+
+// TransitionIssue sends a request to Jira REST API to apply a workflow transition to an Issue with the given ID.
+func (c JiraClient) TransitionIssue(ctx context.Context, id string, transitionID string) error {
+	infoLog(ctx).Printf("Transitioning Jira issue '%s' (transition ID: %s)", id, transitionID)
+
+	endpointURL, err := url.JoinPath(c.ServerURL, c.issuesEndpoint(), id, "transitions")
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"transition": map[string]string{"id": transitionID},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.sendRequest(ctx, "POST", endpointURL, payload)
+	if err != nil {
+		return fmt.Errorf("issue %s could not be transitioned. Reason: %w", id, err)
+	}
+
+	// Jira returns HTTP Status Code 204 if the transition was successful
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("issue %s could not be transitioned. HTTP status code: %d", id, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// tlsVersions maps the TLS versions accepted by the minVersion option to their identifiers.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newJiraTransport creates the transport shared by all requests sent to the Jira server, applying
+// the TLS and proxy settings of a given config. If no proxy URL is set, the proxy is selected
+// by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY env vars.
+func newJiraTransport(config JiraServerConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := newTLSConfig(config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if config.Proxy.URL != "" {
+		proxyURL, err := url.Parse(config.Proxy.URL)
+		if err != nil {
+			return nil, fmt.Errorf("proxy.url: %w", err)
+		}
+
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("proxy.url: '%s' is not an absolute URL", config.Proxy.URL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(config JiraServerTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+
+	if config.InsecureSkipVerify {
+		WarnLog.Println("Verification of the certificate of the Jira server is disabled. Only use this option in test environments")
+	}
+
+	if config.MinVersion != "" {
+		version, ok := tlsVersions[config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("minVersion: unsupported TLS version '%s'. Expected one of: 1.0, 1.1, 1.2, 1.3", config.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if config.CAFile != "" {
+		data, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("caFile: %w", err)
+		}
+
+		// The CA bundle is trusted in addition to the CAs of the system
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("caFile: no PEM-encoded certificates found in '%s'", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, errors.New("both certFile and keyFile have to be set to present a client certificate")
+		}
+
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeServerCA saves the self-signed certificate of a TLS test server, so that it can be used as a CA bundle.
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	path := filepath.Join(t.TempDir(), "ca.crt")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestJiraClientTLS(t *testing.T) {
+	recordSleeps(t)
+	jira, server := newUnstartedFakeJira(t)
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", nil, "")
+
+	testCases := []struct {
+		name      string
+		tlsConfig JiraServerTLSConfig
+		succeeds  bool
+	}{
+		{"untrusted certificate", JiraServerTLSConfig{}, false},
+		{"CA bundle", JiraServerTLSConfig{CAFile: writeServerCA(t, server), MinVersion: "1.2"}, true},
+		{"verification disabled", JiraServerTLSConfig{InsecureSkipVerify: true}, true},
+		{"unsupported TLS version", JiraServerTLSConfig{CAFile: writeServerCA(t, server), MinVersion: "1.3"}, false},
+	}
+
+	for _, tc := range testCases {
+		config := newTestJiraServerConfig(server.URL)
+		config.TLS = tc.tlsConfig
+		config.Retry.MaxAttempts = 1
+
+		client, err := NewJiraClient(config)
+		if err != nil {
+			t.Fatalf("%s: NewJiraClient failed: %s", tc.name, err)
+		}
+
+		_, err = client.GetIssue(context.Background(), "EXAMPLE-15")
+		if succeeded := err == nil; succeeded != tc.succeeds {
+			t.Fatalf("%s: expected the request to succeed: %t, got %v", tc.name, tc.succeeds, err)
+		}
+	}
+}
+
+func TestJiraClientProxy(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", nil, "")
+	target, _ := url.Parse(server.URL)
+
+	var proxied []string
+	proxy := httptest.NewServer(&httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			proxied = append(proxied, r.In.URL.String())
+			r.SetURL(target)
+		},
+	})
+	defer proxy.Close()
+
+	// The host of the Jira server is only reachable through the proxy
+	config := newTestJiraServerConfig("http://jira.example.invalid")
+	config.Proxy.URL = proxy.URL
+
+	client, err := NewJiraClient(config)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	if _, err := client.GetIssue(context.Background(), "EXAMPLE-15"); err != nil {
+		t.Fatalf("GetIssue failed: %s", err)
+	}
+
+	if len(proxied) != 1 || proxied[0] != "http://jira.example.invalid/rest/api/2/issue/EXAMPLE-15" {
+		t.Fatalf("expected the request to be sent through the proxy, got %v", proxied)
+	}
+}
+
+func TestNewJiraTransportErrors(t *testing.T) {
+	invalidCA := filepath.Join(t.TempDir(), "ca.crt")
+	os.WriteFile(invalidCA, []byte("not a certificate"), 0o600)
+
+	testCases := map[string]JiraServerConfig{
+		"tls: minVersion: unsupported TLS version '1.4'":        {TLS: JiraServerTLSConfig{MinVersion: "1.4"}},
+		"tls: caFile: no PEM-encoded certificates found":        {TLS: JiraServerTLSConfig{CAFile: invalidCA}},
+		"tls: both certFile and keyFile have to be set":         {TLS: JiraServerTLSConfig{CertFile: "client.crt"}},
+		"proxy.url: 'proxy.example.com' is not an absolute URL": {Proxy: JiraServerProxyConfig{URL: "proxy.example.com"}},
+	}
+
+	for expected, config := range testCases {
+		if _, err := newJiraTransport(config); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("expected error '%s', got %v", expected, err)
+		}
+	}
+}
@@ -2,12 +2,21 @@ package reporter
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 )
 
+// subtaskMarkerLabelPrefix starts the label identifying the Sub-tasks with test results.
+const subtaskMarkerLabelPrefix = "reporter-subtask-"
+
 // MetadataEntry represents a single key-value pair of metadata set by the user.
 type MetadataEntry struct {
 	Key   string
@@ -38,6 +47,10 @@ type IssueDesiredStateFields struct {
 	Summary     string
 	Description string
 	Labels      []string
+	// Comment is posted to the Sub-task after it has been updated. Empty if comments are disabled
+	Comment string
+	// Transition is the name of the workflow transition applied after the Sub-task has been updated
+	Transition string
 }
 
 func getIssueDesiredStateFields(report AggregateReport, metadata []MetadataEntry, config Config) (f IssueDesiredStateFields, err error) {
@@ -51,34 +64,86 @@ func getIssueDesiredStateFields(report AggregateReport, metadata []MetadataEntry
 	}{report, metadata}
 
 	f.Summary = desiredState.Summary.Contents
-	if desiredState.Summary.IncludeTestCounts {
-		f.Summary = fmt.Sprintf("%s (%d/%d PASSED)", f.Summary, data.Counts.Passed, data.Counts.Total-data.Counts.Skipped)
+	if desiredState.Summary.Template != "" {
+		f.Summary, err = renderSummaryTemplate(desiredState.Summary.Template, data)
+		if err != nil {
+			return f, err
+		}
+	} else if desiredState.Summary.IncludeTestCounts {
+		if data.Counts.Flaky > 0 {
+			f.Summary = fmt.Sprintf("%s (%d/%d PASSED, %d FLAKY)", f.Summary, data.Counts.Passed, data.Counts.Executed(), data.Counts.Flaky)
+		} else {
+			f.Summary = fmt.Sprintf("%s (%d/%d PASSED)", f.Summary, data.Counts.Passed, data.Counts.Executed())
+		}
 	}
 
-	descTemplatePath := desiredState.Description.TemplatePath
-	embeddedTemplatePrefix := "embedded:"
+	buf, err := renderConfiguredTemplate("description", desiredState.Description.TemplatePath, data)
+	if err != nil {
+		return f, err
+	}
+	// Templates can either render the wiki markup or ADF documents. The wiki markup is converted
+	// to ADF by the Jira client if the server expects ADF
+	f.Description = buf.String()
+
+	if desiredState.Comments.Enabled {
+		buf, err := renderConfiguredTemplate("comment", desiredState.Comments.TemplatePath, data)
+		if err != nil {
+			return f, err
+		}
+		f.Comment = buf.String()
+	}
+
+	f.Labels, f.Transition = desiredState.OnFailure.Labels, desiredState.OnFailure.Transition
+	if report.Counts.Failed == 0 && report.Counts.Errored == 0 {
+		f.Labels, f.Transition = desiredState.OnSuccess.Labels, desiredState.OnSuccess.Transition
+	}
+
+	// The marker label identifies the Sub-task in the next runs, regardless of its summary
+	f.Labels = slices.Concat(f.Labels, []string{subtaskMarkerLabel(desiredState.Summary)})
+
+	return f, nil
+}
+
+// renderSummaryTemplate renders the summary of a Sub-task from a template set in the config.
+// The summary is collapsed into a single line and truncated to the length allowed by Jira.
+func renderSummaryTemplate(text string, data any) (string, error) {
+	tmpl, err := template.New("summary").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("summary template could not be parsed: %w", err)
+	}
 
 	var buf bytes.Buffer
-	if strings.HasPrefix(descTemplatePath, embeddedTemplatePrefix) {
-		path := strings.Replace(descTemplatePath, embeddedTemplatePrefix, "", 1)
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("summary template could not be rendered: %w", err)
+	}
+
+	summary := strings.Join(strings.Fields(buf.String()), " ")
+	if summary == "" {
+		return "", errors.New("summary template rendered an empty summary")
+	}
+
+	return truncateSummary(summary), nil
+}
+
+// renderConfiguredTemplate renders a template configured by the user. Paths starting
+// with "embedded:" refer to the templates embedded in the binary.
+func renderConfiguredTemplate(kind string, templatePath string, data any) (buf bytes.Buffer, err error) {
+	embeddedTemplatePrefix := "embedded:"
+
+	if strings.HasPrefix(templatePath, embeddedTemplatePrefix) {
+		path := strings.Replace(templatePath, embeddedTemplatePrefix, "", 1)
 		buf, err = RenderEmbeddedTemplate(path, data)
 		if err != nil {
-			return f, fmt.Errorf("embedded description template could not be rendered: %w", err)
+			return buf, fmt.Errorf("embedded %s template could not be rendered: %w", kind, err)
 		}
 	} else {
-		buf, err = RenderLocalTemplate(descTemplatePath, data)
+		buf, err = RenderLocalTemplate(templatePath, data)
 		if err != nil {
-			return f, fmt.Errorf("local description template could not be rendered: %w", err)
+			return buf, fmt.Errorf("local %s template could not be rendered: %w", kind, err)
 		}
 	}
-	f.Description = buf.String()
 
-	f.Labels = desiredState.OnFailure.Labels
-	if report.Counts.Failed == 0 && report.Counts.Errored == 0 {
-		f.Labels = desiredState.OnSuccess.Labels
-	}
-
-	return f, nil
+	return buf, nil
 }
 
 // LogUploadSummary logs a simple summary message displaying how many AggregateReports were successfully uploaded.
@@ -86,35 +151,254 @@ func LogUploadSummary(logger *log.Logger, uploadedCount int, reports []Aggregate
 	logger.Printf("Summary: %d/%d Aggregate Reports uploaded to Jira", uploadedCount, len(reports))
 }
 
-// UploadSingleAggregateReport uploads a given AggregateReport to its destination.
-func UploadSingleAggregateReport(report AggregateReport, metadata []MetadataEntry, config Config, token string) error {
+// UploadSingleAggregateReport uploads a given AggregateReport to its destination using a given Jira client.
+// The returned result describes the outcome of the upload, including the Sub-task the report was uploaded to.
+func UploadSingleAggregateReport(ctx context.Context, client JiraAPI, report AggregateReport, metadata []MetadataEntry, config Config) (result UploadResult, err error) {
+	startedAt := time.Now()
+	result = NewUploadResult(report)
+	result.StartedAt = &startedAt
+	defer func() {
+		result.Duration = time.Since(startedAt).Seconds()
+		result.Uploaded = err == nil
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}()
+
 	if report.Destination == "" {
-		return errors.New("given report does not have a valid destination")
+		return result, errors.New("given report does not have a valid destination")
+	}
+
+	// The desired state set by the route of the report applies to all its updates
+	config, err = config.withDesiredStateOverride(report.DesiredState)
+	if err != nil {
+		return result, err
+	}
+
+	if err := config.Spec.Jira.DesiredState.Summary.Validate(); err != nil {
+		return result, fmt.Errorf("summary of the Sub-task: %w", err)
 	}
 
 	if report.Counts.Total <= 0 {
-		return errors.New("given report is empty")
+		return result, errors.New("given report is empty")
 	}
 
 	fields, err := getIssueDesiredStateFields(report, metadata, config)
 	if err != nil {
-		return err
+		return result, err
+	}
+	result.Labels = fields.Labels
+
+	result.Subtask, result.Action, err = updateStatusInJira(ctx, client, config, report.Destination, fields)
+	if err != nil {
+		return result, err
+	}
+
+	if fields.Transition != "" {
+		transitions, err := jiraCapability[JiraTransitionsAPI](client, "transitions")
+		if err != nil {
+			return result, err
+		}
+
+		if err := transitionInJira(ctx, transitions, result.Subtask, fields.Transition); err != nil {
+			return result, err
+		}
+	}
+
+	if config.Spec.Jira.DesiredState.Comments.Enabled {
+		comments, err := jiraCapability[JiraCommentsAPI](client, "comments")
+		if err != nil {
+			return result, err
+		}
+
+		if err := commentInJira(ctx, comments, config, result.Subtask, fields.Comment); err != nil {
+			return result, err
+		}
+	}
+
+	if config.Spec.Jira.DesiredState.Attachments.Enabled {
+		attachments, err := jiraCapability[JiraAttachmentsAPI](client, "attachments")
+		if err != nil {
+			return result, err
+		}
+
+		if err := attachToJira(ctx, attachments, config, result.Subtask, report.Attachments); err != nil {
+			return result, err
+		}
+	}
+
+	if config.Spec.Jira.DesiredState.Bugs.Enabled {
+		if err := fileBugsInJira(ctx, client, config, report, metadata); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// attachToJira uploads the attachments of a report to a Sub-task. If configured, the files attached by the account
+// the client is authenticated as in previous runs are deleted once the new attachments have been uploaded.
+func attachToJira(ctx context.Context, client JiraAttachmentsAPI, config Config, subtaskID string, attachments []Attachment) error {
+	var previous []JiraAttachment
+	if config.Spec.Jira.DesiredState.Attachments.ReplacePrevious {
+		user, err := client.GetCurrentUser(ctx)
+		if err != nil {
+			return fmt.Errorf("previous attachments could not be fetched: %w", err)
+		}
+
+		issue, err := client.GetIssue(ctx, subtaskID)
+		if err != nil {
+			return fmt.Errorf("previous attachments could not be fetched: %w", err)
+		}
+
+		for _, attachment := range issue.Attachments {
+			if attachment.AuthorID == user.ID {
+				previous = append(previous, attachment)
+			}
+		}
+	}
+
+	for _, attachment := range attachments {
+		if err := client.AddAttachment(ctx, subtaskID, attachment); err != nil {
+			return err
+		}
 	}
 
-	if err := updateStatusInJira(config, token, report.Destination, fields); err != nil {
+	for _, attachment := range previous {
+		if err := client.DeleteAttachment(ctx, attachment.ID); err != nil {
+			return fmt.Errorf("previous attachments could not be replaced: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// transitionInJira applies a workflow transition to a Sub-task. The transition is matched by its name or the name
+// of the status it leads to. Transitions that are not available from the current status are skipped.
+func transitionInJira(ctx context.Context, client JiraTransitionsAPI, subtaskID string, name string) error {
+	transitions, err := client.GetTransitions(ctx, subtaskID)
+	if err != nil {
+		return fmt.Errorf("transition '%s' could not be applied: %w", name, err)
+	}
+
+	for _, transition := range transitions {
+		if strings.EqualFold(transition.Name, name) || strings.EqualFold(transition.ToStatus, name) {
+			if err := client.TransitionIssue(ctx, subtaskID, transition.ID); err != nil {
+				return fmt.Errorf("transition '%s' could not be applied: %w", name, err)
+			}
+			return nil
+		}
+	}
+
+	infoLog(ctx).Printf("Transition '%s' is not available for issue '%s' in its current status. Skipping", name, subtaskID)
+	return nil
+}
+
+// commentInJira posts the comment describing the current run to a Sub-task. If configured, the comments posted
+// by the account the client is authenticated as are pruned, keeping only the comments of the last runs.
+func commentInJira(ctx context.Context, client JiraCommentsAPI, config Config, subtaskID string, comment string) error {
+	if err := client.AddComment(ctx, subtaskID, comment); err != nil {
 		return err
 	}
 
+	keepLast := config.Spec.Jira.DesiredState.Comments.KeepLast
+	if keepLast <= 0 {
+		return nil
+	}
+
+	user, err := client.GetCurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("comments could not be pruned: %w", err)
+	}
+
+	comments, err := client.GetComments(ctx, subtaskID)
+	if err != nil {
+		return fmt.Errorf("comments could not be pruned: %w", err)
+	}
+
+	var own []JiraComment
+	for _, comment := range comments {
+		if comment.AuthorID == user.ID {
+			own = append(own, comment)
+		}
+	}
+
+	// Comments are ordered from the oldest to the newest
+	for _, comment := range own[:max(len(own)-keepLast, 0)] {
+		if err := client.DeleteComment(ctx, subtaskID, comment.ID); err != nil {
+			return fmt.Errorf("comments could not be pruned: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// UploadAggregateReports takes multiple AggregateReports and uploads them all to their corresponding destinations.
-func UploadAggregateReports(reports []AggregateReport, metadata []MetadataEntry, config Config, token string) error {
+// UploadAggregateReports takes multiple AggregateReports and uploads them all to their corresponding destinations
+// using a given Jira client (see NewJiraClient). Reports are uploaded concurrently by the number of workers set
+// in the server config (one by default). Messages logged while uploading a report are kept together and logged
+// in the same order as the reports are given. Once the context is done, the remaining reports are not uploaded.
+// A result is returned for each report, in the same order as the reports, even if some of them failed to be uploaded.
+func UploadAggregateReports(ctx context.Context, client JiraAPI, reports []AggregateReport, metadata []MetadataEntry, config Config) ([]UploadResult, error) {
+	workers := min(max(config.Spec.Jira.Server.MaxConcurrency, 1), max(len(reports), 1))
+	// Bugs filed for one report are known to all other reports
+	ctx = withBugRegistry(ctx, &bugRegistry{})
+
+	type uploadResult struct {
+		UploadResult
+		logs *logBuffer
+		err  error
+		done chan struct{}
+	}
+
+	results := make([]uploadResult, len(reports))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range reports {
+			jobs <- i
+		}
+	}()
+
+	for range workers {
+		go func() {
+			for i := range jobs {
+				result := &results[i]
+
+				// Messages of a report uploaded by a single worker can be logged right away
+				uploadCtx := ctx
+				if workers > 1 {
+					result.logs = &logBuffer{}
+					uploadCtx = withLogBuffer(ctx, result.logs)
+				}
+
+				if result.err = ctx.Err(); result.err == nil {
+					result.UploadResult, result.err = UploadSingleAggregateReport(uploadCtx, client, reports[i], metadata, config)
+				} else {
+					result.UploadResult = NewUploadResult(reports[i])
+					result.Error = result.err.Error()
+				}
+				close(result.done)
+			}
+		}()
+	}
+
 	uploadedCount := 0
+	uploadResults := make([]UploadResult, len(reports))
+	for i := range results {
+		result := &results[i]
+		<-result.done
+		uploadResults[i] = result.UploadResult
+
+		if result.logs != nil {
+			result.logs.flush()
+		}
 
-	for i, report := range reports {
-		if err := UploadSingleAggregateReport(report, metadata, config, token); err != nil {
-			WarnLog.Printf("Aggregate Report %d) could not be uploaded: %s", i+1, err)
+		if result.err != nil {
+			WarnLog.Printf("Aggregate Report %d) could not be uploaded: %s", i+1, result.err)
 		} else {
 			uploadedCount++
 		}
@@ -123,74 +407,164 @@ func UploadAggregateReports(reports []AggregateReport, metadata []MetadataEntry,
 	LogUploadSummary(InfoLog, uploadedCount, reports)
 
 	if uploadedCount != len(reports) {
-		return fmt.Errorf("%d Aggregate Report(s) failed to be uploaded", len(reports)-uploadedCount)
+		return uploadResults, fmt.Errorf("%d Aggregate Report(s) failed to be uploaded", len(reports)-uploadedCount)
+	}
+
+	return uploadResults, nil
+}
+
+// Validate checks whether the summary can identify the Sub-tasks with test results, i.e. whether
+// either the contents or the template are set.
+func (s JiraIssueDesiredStateSummaryConfig) Validate() error {
+	if s.Contents == "" && s.Template == "" {
+		return errors.New("either contents or template has to be set")
 	}
 
 	return nil
 }
 
+// subtaskMarkerLabel returns the label identifying the Sub-tasks with test results. The label is derived from
+// the summary contents set in the config, or from the summary template if the contents are empty, so that
+// Sub-tasks of routes with their own summary are kept apart.
+func subtaskMarkerLabel(summary JiraIssueDesiredStateSummaryConfig) string {
+	key := summary.Contents
+	if key == "" {
+		key = summary.Template
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	return subtaskMarkerLabelPrefix + hex.EncodeToString(hash[:])[:12]
+}
+
+// isJiraSubtaskValidDestination checks whether a Sub-task holds the test results. Sub-tasks are identified by
+// their marker label. Sub-tasks created before the label was introduced are matched by their summary instead,
+// unless the summary contents are empty, which every summary would contain.
 func isJiraSubtaskValidDestination(issue *JiraIssue, config Config) bool {
-	desiredSummaryContents := config.Spec.Jira.DesiredState.Summary.Contents
+	summary := config.Spec.Jira.DesiredState.Summary
+	if slices.Contains(issue.Labels, subtaskMarkerLabel(summary)) {
+		return true
+	}
+
+	desiredSummaryContents := summary.Contents
+	if desiredSummaryContents == "" {
+		return false
+	}
+
 	return strings.Contains(issue.Summary, desiredSummaryContents)
 }
 
-func updateStatusInJira(config Config, token string, issueID string, fields IssueDesiredStateFields) error {
-	client := JiraClient{
-		ServerURL:   config.Spec.Jira.Server.URL,
-		AccessToken: token,
+// findSubtaskInJira returns the ID of the Sub-task holding the test results under a given Story, or an empty
+// string if there is none yet. Sub-tasks listed in the Story lack their labels, so Sub-tasks with the marker
+// label are searched for first, if the client supports searching.
+func findSubtaskInJira(ctx context.Context, client JiraAPI, config Config, story JiraIssue) (string, error) {
+	if search, ok := client.(JiraSearchAPI); ok {
+		marker := subtaskMarkerLabel(config.Spec.Jira.DesiredState.Summary)
+		jql := fmt.Sprintf("parent = %s AND labels = %s", jqlQuote(story.ID), jqlQuote(marker))
+		issues, err := search.SearchIssues(ctx, jql)
+		if err != nil {
+			return "", fmt.Errorf("sub-tasks could not be searched: %w", err)
+		}
+
+		if len(issues) > 0 {
+			return issues[0].ID, nil
+		}
+	}
+
+	for _, child := range story.SubTasks {
+		if isJiraSubtaskValidDestination(child, config) {
+			return child.ID, nil
+		}
 	}
 
-	issue, err := client.GetIssue(issueID)
+	return "", nil
+}
+
+// updateStatusInJira brings the Sub-task with test results to the desired state. The destination is either
+// the Sub-task itself, or a Story whose matching Sub-task is updated or created. It returns the ID of the Sub-task
+// and what has been done to it.
+func updateStatusInJira(ctx context.Context, client JiraAPI, config Config, issueID string, fields IssueDesiredStateFields) (string, SubtaskAction, error) {
+	issue, err := client.GetIssue(ctx, issueID)
 	if err != nil {
-		return err
+		return "", "", err
 	}
 
-	InfoLog.Printf("Processing issue '%s' type: '%s', summary: '%s'", issueID, issue.Type, issue.Summary)
+	infoLog(ctx).Printf("Processing issue '%s' type: '%s', summary: '%s'", issueID, issue.Type, issue.Summary)
 	if issue.Type == "Sub-task" {
 		// Check the Issue Summary to ensure we are not overwriting an incorrect Sub-task by mistake
 		if isJiraSubtaskValidDestination(&issue, config) {
-			if err := client.UpdateIssue(issueID, fields.Summary, fields.Description, fields.Labels); err != nil {
-				return fmt.Errorf("sub-task could not be updated: %w", err)
+			action, err := updateSubtaskInJira(ctx, client, issue, fields)
+			if err != nil {
+				return "", "", err
 			}
+
+			return issueID, action, nil
 		} else {
-			desiredSummaryContents := config.Spec.Jira.DesiredState.Summary.Contents
-			return fmt.Errorf("summary of target Sub-task '%s' does not contain '%s'", issueID, desiredSummaryContents)
+			summary := config.Spec.Jira.DesiredState.Summary
+			if summary.Contents == "" {
+				return "", "", fmt.Errorf("target Sub-task '%s' is not labeled with '%s'", issueID, subtaskMarkerLabel(summary))
+			}
+			return "", "", fmt.Errorf("target Sub-task '%s' is not labeled with '%s' and its summary does not contain '%s'",
+				issueID, subtaskMarkerLabel(summary), summary.Contents)
 		}
 	} else if issue.Type == "Story" {
 		// Ensure the Story has a proper prefix and labels
 		requiredPrefix := config.Spec.Jira.Discovery.Summary.RequiredPrefix
 		if requiredPrefix != "" && !strings.HasPrefix(issue.Summary, requiredPrefix) {
-			return fmt.Errorf("summary of target Story '%s' does not have the required prefix '%s'", issueID, requiredPrefix)
+			return "", "", fmt.Errorf("summary of target Story '%s' does not have the required prefix '%s'", issueID, requiredPrefix)
 		}
 
 		requiredLabels := config.Spec.Jira.Discovery.Labels.RequiredAnyOf
 		if requiredLabels != nil && !issue.IsLabeledWithAnyOf(requiredLabels) {
-			return fmt.Errorf("target Story '%s' is not labeled with any of the following: %v", issueID, requiredLabels)
+			return "", "", fmt.Errorf("target Story '%s' is not labeled with any of the following: %v", issueID, requiredLabels)
 		}
 
-		var subtaskID string
-		for _, child := range issue.SubTasks {
-			if isJiraSubtaskValidDestination(child, config) {
-				subtaskID = child.ID
-				InfoLog.Printf("Found a matching Sub-task '%s' for issue '%s'", subtaskID, issueID)
-				break
-			}
+		subtaskID, err := findSubtaskInJira(ctx, client, config, issue)
+		if err != nil {
+			return "", "", err
 		}
 
 		if subtaskID != "" {
-			if err := client.UpdateIssue(subtaskID, fields.Summary, fields.Description, fields.Labels); err != nil {
-				return fmt.Errorf("sub-task could not be updated: %w", err)
+			infoLog(ctx).Printf("Found a matching Sub-task '%s' for issue '%s'", subtaskID, issueID)
+
+			// Sub-tasks listed in the parent issue lack most of their fields
+			subtask, err := client.GetIssue(ctx, subtaskID)
+			if err != nil {
+				return "", "", err
 			}
-		} else {
-			newSubtaskID, err := client.CreateSubtask(issueID, fields.Summary, fields.Description, fields.Labels)
+
+			action, err := updateSubtaskInJira(ctx, client, subtask, fields)
 			if err != nil {
-				return fmt.Errorf("sub-task could not be created: %w", err)
+				return "", "", err
 			}
-			InfoLog.Printf("Created new Sub-task '%s' for issue '%s'", newSubtaskID, issueID)
+
+			return subtaskID, action, nil
 		}
-	} else {
-		return fmt.Errorf("target issue has to be either a Story or a Sub-task. Got '%s' instead", issue.Type)
+
+		newSubtaskID, err := client.CreateSubtask(ctx, issueID, fields.Summary, fields.Description, fields.Labels)
+		if err != nil {
+			return "", "", fmt.Errorf("sub-task could not be created: %w", err)
+		}
+		infoLog(ctx).Printf("Created new Sub-task '%s' for issue '%s'", newSubtaskID, issueID)
+
+		return newSubtaskID, SubtaskActionCreated, nil
 	}
 
-	return nil
+	return "", "", fmt.Errorf("target issue has to be either a Story or a Sub-task. Got '%s' instead", issue.Type)
+}
+
+// updateSubtaskInJira updates a Sub-task with its desired state. The update is skipped if the Sub-task is already
+// up to date, so that watchers are not notified and the history of the Sub-task is not cluttered on every run.
+func updateSubtaskInJira(ctx context.Context, client JiraAPI, subtask JiraIssue, fields IssueDesiredStateFields) (SubtaskAction, error) {
+	changed, unchanged := diffIssueFields(subtask, fields)
+	if len(changed) == 0 {
+		infoLog(ctx).Printf("Sub-task '%s' is up to date. Skipping the update", subtask.ID)
+		return SubtaskActionUnchanged, nil
+	}
+
+	infoLog(ctx).Printf("Sub-task '%s' is out of date. Changed fields: %v, unchanged fields: %v", subtask.ID, changed, unchanged)
+	if err := client.UpdateIssue(ctx, subtask.ID, fields.Summary, fields.Description, fields.Labels); err != nil {
+		return "", fmt.Errorf("sub-task could not be updated: %w", err)
+	}
+
+	return SubtaskActionUpdated, nil
 }
@@ -0,0 +1,509 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createUploaderTestConfig(serverURL string) Config {
+	var config Config
+	config.Spec.Jira.Server = JiraServerConfig{
+		URL:   serverURL,
+		Auth:  JiraServerAuthConfig{Token: SecretConfig{Value: "secret-token"}},
+		Retry: JiraServerRetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+	config.Spec.Jira.DesiredState.Summary.Contents = "Automated test suite execution status"
+	config.Spec.Jira.DesiredState.Summary.IncludeTestCounts = true
+	config.Spec.Jira.DesiredState.Description.TemplatePath = "embedded:templates/jira_subtask_desc.tmpl"
+	config.Spec.Jira.DesiredState.OnSuccess.Labels = []string{"PASSED"}
+	config.Spec.Jira.DesiredState.OnFailure.Labels = []string{"FAILED"}
+
+	return config
+}
+
+func createUploaderTestReport(destination string) AggregateReport {
+	report := AggregateReport{
+		Destination: destination,
+		TestSuites: []TestSuite{{
+			Name:      "Networking",
+			Counts:    Counts{Passed: 1, Failed: 1, Total: 2},
+			TestCases: []TestCase{{Name: "one", Status: "passed"}, {Name: "two", Status: "failed", Message: "timed out"}},
+		}},
+	}
+	report.AggregateCounts()
+
+	return report
+}
+
+func TestUploadAggregateReports(t *testing.T) {
+	recordSleeps(t)
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-15", "Story", "QE Story", nil, "")
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+
+	// Temporary failures are retried
+	jira.responses = []fakeJiraResponse{{StatusCode: http.StatusServiceUnavailable}}
+
+	config := createUploaderTestConfig(server.URL)
+	reports := []AggregateReport{createUploaderTestReport("EXAMPLE-15"), createUploaderTestReport("EXAMPLE-20")}
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	results, err := UploadAggregateReports(context.Background(), client, reports, nil, config)
+	if err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	story := jira.issue("EXAMPLE-15")
+	if len(story.Fields.SubTasks) != 1 {
+		t.Fatalf("expected a Sub-task to be created under EXAMPLE-15, got %d", len(story.Fields.SubTasks))
+	}
+
+	for _, subtask := range []*apiResponseIssue{story.Fields.SubTasks[0], jira.issue("EXAMPLE-21")} {
+		if subtask.Fields.Summary != "Automated test suite execution status (1/2 PASSED)" {
+			t.Fatalf("unexpected summary of %s: '%s'", subtask.Key, subtask.Fields.Summary)
+		}
+
+		// Sub-tasks are labeled with the marker identifying them in the next runs
+		labels := []string{"FAILED", subtaskMarkerLabel(JiraIssueDesiredStateSummaryConfig{Contents: "Automated test suite execution status"})}
+		if !strings.Contains(string(subtask.Fields.Description), "timed out") || !slices.Equal(subtask.Fields.Labels, labels) {
+			t.Fatalf("unexpected contents of %s: %+v", subtask.Key, subtask.Fields)
+		}
+	}
+
+	// Results point to the Sub-tasks the reports have been uploaded to
+	expected := []UploadResult{
+		{Destination: "EXAMPLE-15", Subtask: story.Fields.SubTasks[0].Key, Action: SubtaskActionCreated},
+		{Destination: "EXAMPLE-20", Subtask: "EXAMPLE-21", Action: SubtaskActionUpdated},
+	}
+	for i, result := range results {
+		if result.Destination != expected[i].Destination || result.Subtask != expected[i].Subtask || result.Action != expected[i].Action ||
+			!result.Uploaded || result.Counts.Total != 2 || result.Labels[0] != "FAILED" || result.StartedAt == nil {
+			t.Fatalf("unexpected result of report %d: %+v", i+1, result)
+		}
+	}
+}
+
+func TestUploadAggregateReportsConcurrently(t *testing.T) {
+	jira, server := newFakeJira(t)
+
+	var reports []AggregateReport
+	for i := range 12 {
+		key := fmt.Sprintf("EXAMPLE-%d", i+1)
+		jira.addIssue(key, "Story", "QE Story", nil, "")
+		reports = append(reports, createUploaderTestReport(key))
+	}
+	reports = append(reports, createUploaderTestReport("EXAMPLE-404"))
+
+	var logs bytes.Buffer
+	for _, logger := range []*log.Logger{InfoLog, WarnLog} {
+		output := logger.Writer()
+		logger.SetOutput(&logs)
+		t.Cleanup(func() { logger.SetOutput(output) })
+	}
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.Server.MaxConcurrency = 4
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	results, err := UploadAggregateReports(context.Background(), client, reports, nil, config)
+	if err == nil || err.Error() != "1 Aggregate Report(s) failed to be uploaded" {
+		t.Fatalf("expected 1 report to fail to be uploaded, got %v", err)
+	}
+
+	if len(results) != len(reports) || results[12].Uploaded || !strings.Contains(results[12].Error, "HTTP status code: 404") {
+		t.Fatalf("expected a result for each report, including the failed one, got %+v", results)
+	}
+
+	for _, report := range reports[:12] {
+		if subtasks := jira.issue(report.Destination).Fields.SubTasks; len(subtasks) != 1 {
+			t.Fatalf("expected a Sub-task to be created under %s, got %d", report.Destination, len(subtasks))
+		}
+	}
+
+	// Messages of each report are logged together, in the same order as the reports are given
+	var destinations []string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if _, key, found := strings.Cut(line, "Getting Jira issue '"); found {
+			destinations = append(destinations, strings.TrimSuffix(key, "'"))
+		}
+	}
+
+	for i, report := range reports {
+		if len(destinations) != len(reports) || destinations[i] != report.Destination {
+			t.Fatalf("expected the messages to be logged in the order of the reports, got %v", destinations)
+		}
+	}
+
+	if !strings.Contains(logs.String(), "Aggregate Report 13) could not be uploaded") || !strings.Contains(logs.String(), "Summary: 12/13") {
+		t.Fatalf("expected the failed report to be logged in the summary, got:\n%s", logs.String())
+	}
+}
+
+func TestUploadAggregateReportsSkipsUnchangedSubtasks(t *testing.T) {
+	// Descriptions are returned as ADF documents by Jira Cloud
+	for _, flavor := range []JiraFlavor{JiraFlavorDataCenter, JiraFlavorCloud} {
+		jira, server := newFakeJira(t)
+		jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+		jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+
+		config := createUploaderTestConfig(server.URL)
+		config.Spec.Jira.Server.Flavor = flavor
+		config.Spec.Jira.Server.Email = "user@example.com"
+
+		client, err := NewJiraClient(config.Spec.Jira.Server)
+		if err != nil {
+			t.Fatalf("NewJiraClient failed: %s", err)
+		}
+
+		var updates []int
+		var actions []SubtaskAction
+		for range 2 {
+			jira.mu.Lock()
+			jira.requests = nil
+			jira.mu.Unlock()
+
+			results, err := UploadAggregateReports(context.Background(), client, []AggregateReport{createUploaderTestReport("EXAMPLE-20")}, nil, config)
+			if err != nil {
+				t.Fatalf("UploadAggregateReports failed: %s", err)
+			}
+			actions = append(actions, results[0].Action)
+
+			count := 0
+			for _, request := range jira.requests {
+				if strings.HasPrefix(request, "PUT ") {
+					count++
+				}
+			}
+			updates = append(updates, count)
+		}
+
+		if updates[0] != 1 || updates[1] != 0 || actions[1] != SubtaskActionUnchanged {
+			t.Fatalf("%s: expected the Sub-task to be updated in the first run only, got %v updates (%v)", flavor, updates, actions)
+		}
+	}
+}
+
+func TestUploadAggregateReportsWithDesiredStateOverrides(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+	jira.addIssue("EXAMPLE-22", "Sub-task", "Team A test results (0/1 PASSED)", nil, "EXAMPLE-20")
+
+	config := createUploaderTestConfig(server.URL)
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	report := createUploaderTestReport("EXAMPLE-20")
+	report.DesiredState = JiraIssueDesiredStateOverrideConfig{
+		"summary":   map[string]any{"contents": "Team A test results"},
+		"onFailure": map[string]any{"labels": []any{"TEAM-A-FAILED"}},
+	}
+
+	// The Sub-task matching the summary of the override is updated, with the labels of the override
+	results, err := UploadAggregateReports(context.Background(), client, []AggregateReport{report}, nil, config)
+	if err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	subtask := jira.issue("EXAMPLE-22")
+	if results[0].Subtask != "EXAMPLE-22" || subtask.Fields.Summary != "Team A test results (1/2 PASSED)" {
+		t.Fatalf("expected EXAMPLE-22 to be updated, got %+v and summary '%s'", results[0], subtask.Fields.Summary)
+	}
+
+	if !slices.Equal(subtask.Fields.Labels, []string{"TEAM-A-FAILED", subtaskMarkerLabel(JiraIssueDesiredStateSummaryConfig{Contents: "Team A test results"})}) {
+		t.Fatalf("expected the labels of the override to be set, got %v", subtask.Fields.Labels)
+	}
+
+	if summary := jira.issue("EXAMPLE-21").Fields.Summary; summary != "Automated test suite execution status (0/1 PASSED)" {
+		t.Fatalf("expected EXAMPLE-21 to be left untouched, got summary '%s'", summary)
+	}
+
+	// Sub-tasks given as the destination have to match the summary of the override
+	report.Destination = "EXAMPLE-21"
+	if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{report}, nil, config); err == nil {
+		t.Fatalf("expected the upload to EXAMPLE-21 to fail, as its summary does not match the override")
+	}
+}
+
+func TestUploadAggregateReportsWithSummaryTemplate(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Manual test plan", nil, "EXAMPLE-20")
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.Summary.Template = `{{ range .Metadata }}[{{ .Value }}] {{ end }}
+		Test results: {{ printf "%.0f" .Counts.PassRate }}% passed, {{ .Counts.Flaky }} flaky`
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	// The Sub-task is found by its marker label in the next run, although its summary has changed
+	var subtasks []string
+	for _, release := range []string{"4.16", "4.17"} {
+		metadata := []MetadataEntry{{Key: "release", Value: release}}
+		results, err := UploadAggregateReports(context.Background(), client, []AggregateReport{createUploaderTestReport("EXAMPLE-20")}, metadata, config)
+		if err != nil {
+			t.Fatalf("UploadAggregateReports failed: %s", err)
+		}
+
+		summary := jira.issue(results[0].Subtask).Fields.Summary
+		if expected := fmt.Sprintf("[%s] Test results: 50%% passed, 0 flaky", release); summary != expected {
+			t.Fatalf("expected summary '%s', got '%s'", expected, summary)
+		}
+		subtasks = append(subtasks, results[0].Subtask)
+	}
+
+	if subtasks[0] != subtasks[1] || subtasks[0] == "EXAMPLE-21" || len(jira.issue("EXAMPLE-20").Fields.SubTasks) != 2 {
+		t.Fatalf("expected a single Sub-task to be created and updated, got %v", subtasks)
+	}
+
+	for template, expected := range map[string]string{
+		"{{ .Counts.Passed ":       "summary template could not be parsed",
+		"{{ .Unknown }}":           "summary template could not be rendered",
+		"{{ if false }}x{{ end }}": "summary template rendered an empty summary",
+	} {
+		config.Spec.Jira.DesiredState.Summary.Template = template
+		if _, err := getIssueDesiredStateFields(createUploaderTestReport("EXAMPLE-20"), nil, config); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("expected an error starting with '%s' for template '%s', got %v", expected, template, err)
+		}
+	}
+}
+
+func TestUploadAggregateReportsWithoutSummaryContents(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Manual test plan", nil, "EXAMPLE-20")
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.Summary.Contents = ""
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	// Sub-tasks are told apart by the template, and unrelated Sub-tasks are never matched by their summary
+	var subtasks []string
+	for _, template := range []string{"Team A: {{ .Counts.Passed }} passed", "Team B: {{ .Counts.Passed }} passed", "Team A: {{ .Counts.Passed }} passed"} {
+		config.Spec.Jira.DesiredState.Summary.Template = template
+		results, err := UploadAggregateReports(context.Background(), client, []AggregateReport{createUploaderTestReport("EXAMPLE-20")}, nil, config)
+		if err != nil {
+			t.Fatalf("UploadAggregateReports failed: %s", err)
+		}
+		subtasks = append(subtasks, results[0].Subtask)
+	}
+
+	if subtasks[0] == "EXAMPLE-21" || subtasks[0] == subtasks[1] || subtasks[0] != subtasks[2] {
+		t.Fatalf("expected a separate Sub-task for each template, got %v", subtasks)
+	}
+
+	if summary := jira.issue("EXAMPLE-21").Fields.Summary; summary != "Manual test plan" {
+		t.Fatalf("expected the unrelated Sub-task to be kept, got summary '%s'", summary)
+	}
+
+	config.Spec.Jira.DesiredState.Summary.Template = ""
+	if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{createUploaderTestReport("EXAMPLE-20")}, nil, config); err == nil {
+		t.Fatalf("expected an error for a summary without contents and template")
+	}
+}
+
+func TestUploadAggregateReportsWithComments(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+
+	human := apiUser{Key: "JIRAUSER2", Name: "jdoe"}
+	jira.addComment("EXAMPLE-21", jira.user, "run 1")
+	jira.addComment("EXAMPLE-21", human, "Looking into it")
+	jira.addComment("EXAMPLE-21", jira.user, "run 2")
+	jira.addComment("EXAMPLE-21", jira.user, "run 3")
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.Comments = JiraIssueDesiredStateCommentsConfig{
+		Enabled:      true,
+		TemplatePath: "embedded:templates/jira_subtask_comment.tmpl",
+		KeepLast:     2,
+	}
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	metadata := []MetadataEntry{{Key: "Build", Value: "42"}}
+	if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{createUploaderTestReport("EXAMPLE-20")}, metadata, config); err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	comments := jira.comments["EXAMPLE-21"]
+	if len(comments) != 3 || comments[0].Body != "Looking into it" || comments[1].Body != "run 3" {
+		t.Fatalf("expected the oldest comments of the service account to be pruned, got %+v", comments)
+	}
+
+	for _, expected := range []string{"Test run failed", "| 1 | 1 | 0 | 0 | 0 | *2* |", "Networking: two", "| Build | 42 |"} {
+		if !strings.Contains(string(comments[2].Body), expected) {
+			t.Fatalf("expected the comment of the current run to contain '%s', got:\n%s", expected, comments[2].Body)
+		}
+	}
+}
+
+func TestUploadAggregateReportsWithAttachments(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+	jira.addAttachment("EXAMPLE-21", jira.user, "test-reports.tar.gz", []byte("previous run"))
+	jira.addAttachment("EXAMPLE-21", apiUser{Key: "JIRAUSER2"}, "notes.txt", []byte("manual upload"))
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.Attachments = JiraIssueDesiredStateAttachmentsConfig{Enabled: true, ReplacePrevious: true}
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	report := createUploaderTestReport("EXAMPLE-20")
+	report.Attachments = []Attachment{{Name: "test-reports.tar.gz", Data: []byte("current run")}}
+	if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{report}, nil, config); err != nil {
+		t.Fatalf("UploadAggregateReports failed: %s", err)
+	}
+
+	attachments := jira.issue("EXAMPLE-21").Fields.Attachments
+	if len(attachments) != 2 || attachments[0].Filename != "notes.txt" || attachments[1].Filename != "test-reports.tar.gz" {
+		t.Fatalf("expected the previous attachment of the service account to be replaced, got %+v", attachments)
+	}
+
+	if data := jira.attachments[attachments[1].ID]; string(data) != "current run" {
+		t.Fatalf("unexpected contents of the new attachment: '%s'", data)
+	}
+}
+
+func TestUploadAggregateReportsWithTransitions(t *testing.T) {
+	jira, server := newFakeJira(t)
+	jira.addIssue("EXAMPLE-20", "Story", "QE Another story", nil, "")
+	jira.addIssue("EXAMPLE-21", "Sub-task", "Automated test suite execution status (0/1 PASSED)", nil, "EXAMPLE-20")
+
+	config := createUploaderTestConfig(server.URL)
+	config.Spec.Jira.DesiredState.OnSuccess.Transition = "Done"
+	config.Spec.Jira.DesiredState.OnFailure.Transition = "start progress"
+
+	client, err := NewJiraClient(config.Spec.Jira.Server)
+	if err != nil {
+		t.Fatalf("NewJiraClient failed: %s", err)
+	}
+
+	passed := createUploaderTestReport("EXAMPLE-20")
+	passed.TestSuites[0].TestCases[1].Status = "passed"
+	passed.TestSuites[0].Counts = Counts{Passed: 2, Total: 2}
+	passed.AggregateCounts()
+
+	// Transitions are matched by their name or the name of the target status, and transitions
+	// that are not available in the current status are skipped
+	for _, step := range []struct {
+		report   AggregateReport
+		expected string
+	}{
+		{createUploaderTestReport("EXAMPLE-20"), "In Progress"},
+		{createUploaderTestReport("EXAMPLE-20"), "In Progress"},
+		{passed, "Done"},
+		{passed, "Done"},
+	} {
+		if _, err := UploadAggregateReports(context.Background(), client, []AggregateReport{step.report}, nil, config); err != nil {
+			t.Fatalf("UploadAggregateReports failed: %s", err)
+		}
+
+		if status := jira.issue("EXAMPLE-21").Fields.Status.Name; status != step.expected {
+			t.Fatalf("expected the Sub-task to be in status '%s', got '%s'", step.expected, status)
+		}
+	}
+}
+
+// mockJiraAPI is an in-memory implementation of JiraAPI, which records the updates instead of sending them.
+type mockJiraAPI struct {
+	issues  map[string]JiraIssue
+	updated map[string]IssueDesiredStateFields
+	created map[string]IssueDesiredStateFields
+}
+
+func (m *mockJiraAPI) GetIssue(_ context.Context, id string) (JiraIssue, error) {
+	issue, ok := m.issues[id]
+	if !ok {
+		return issue, fmt.Errorf("issue %s could not be fetched. HTTP status code: %d", id, http.StatusNotFound)
+	}
+
+	return issue, nil
+}
+
+func (m *mockJiraAPI) UpdateIssue(_ context.Context, id string, summary string, description string, labels []string) error {
+	m.updated[id] = IssueDesiredStateFields{Summary: summary, Description: description, Labels: labels}
+	return nil
+}
+
+func (m *mockJiraAPI) CreateSubtask(_ context.Context, parent string, summary string, description string, labels []string) (string, error) {
+	m.created[parent] = IssueDesiredStateFields{Summary: summary, Description: description, Labels: labels}
+	return parent + "-1", nil
+}
+
+func TestUploadAggregateReportsWithMockClient(t *testing.T) {
+	client := &mockJiraAPI{
+		issues: map[string]JiraIssue{
+			"EXAMPLE-15": {ID: "EXAMPLE-15", Type: "Story", Summary: "QE Story"},
+			"EXAMPLE-16": {ID: "EXAMPLE-16", Type: "Story", Summary: "Story without the required prefix"},
+			"EXAMPLE-17": {ID: "EXAMPLE-17", Type: "Sub-task", Summary: "Unrelated Sub-task"},
+			"EXAMPLE-18": {ID: "EXAMPLE-18", Type: "Bug", Summary: "QE Bug"},
+		},
+		updated: map[string]IssueDesiredStateFields{},
+		created: map[string]IssueDesiredStateFields{},
+	}
+
+	config := createUploaderTestConfig("")
+	config.Spec.Jira.Discovery.Summary.RequiredPrefix = "QE"
+
+	var reports []AggregateReport
+	for _, dest := range []string{"EXAMPLE-15", "EXAMPLE-16", "EXAMPLE-17", "EXAMPLE-18", "EXAMPLE-404"} {
+		reports = append(reports, createUploaderTestReport(dest))
+	}
+
+	_, err := UploadAggregateReports(context.Background(), client, reports, nil, config)
+	if err == nil || err.Error() != "4 Aggregate Report(s) failed to be uploaded" {
+		t.Fatalf("expected 4 reports to fail to be uploaded, got %v", err)
+	}
+
+	if len(client.created) != 1 || len(client.updated) != 0 || client.created["EXAMPLE-15"].Labels[0] != "FAILED" {
+		t.Fatalf("expected only a Sub-task under EXAMPLE-15 to be created, got %v and %v", client.created, client.updated)
+	}
+
+	// Remaining reports are not uploaded once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.created = map[string]IssueDesiredStateFields{}
+	if _, err := UploadAggregateReports(ctx, client, reports[:1], nil, config); err == nil || len(client.created) != 0 {
+		t.Fatalf("expected no reports to be uploaded after the context was cancelled, got %v", err)
+	}
+
+	// Optional features fail if the client does not provide the operations they use
+	config.Spec.Jira.DesiredState.OnFailure.Transition = "start progress"
+	results, _ := UploadAggregateReports(context.Background(), client, reports[:1], nil, config)
+	if !strings.Contains(results[0].Error, "JiraTransitionsAPI") {
+		t.Fatalf("expected the upload to fail for a client without transitions, got '%s'", results[0].Error)
+	}
+}